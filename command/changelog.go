@@ -0,0 +1,34 @@
+package command
+
+import (
+	"errors"
+	"flag"
+)
+
+// Changelog for `gqlmerge changelog`
+type Changelog struct {
+	Args   []string
+	Old    string
+	Paths  []string
+	Output string
+}
+
+func (c *Changelog) Check() error {
+	fs := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, ChangelogUsage())
+	}
+
+	args := fs.Args()
+	if len(args) < 3 {
+		return errors.New(ChangelogUsage())
+	}
+
+	c.Old = args[0]
+	c.Paths = args[1 : len(args)-1]
+	c.Output = args[len(args)-1]
+
+	return checkPaths(args[:len(args)-1])
+}
@@ -1,19 +1,51 @@
 package command
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	gql "github.com/mununki/gqlmerge/lib"
 )
 
+// Stdout is the output to write the schema into the standard output.
+const Stdout = "-"
+
 // Command for gqlmerge
 type Command struct {
-	Args   []string
-	Paths  []string
+	Args      []string
+	Paths     []string
+	Output    string
+	Indent    string
+	Format    gql.Format
+	Validate  bool
+	CheckOnly bool
+	Watch     bool
+	CacheDir  string
+	Contracts []Contract
+
+	// Name, Dir, Filter, OnConflict, Lint and Plugins are set for the
+	// projects of the configuration file.
+	Name       string
+	Dir        string
+	Filter     *gql.Filter
+	OnConflict gql.ConflictPolicy
+	Lint       map[string]string
+	Plugins    []string
+
+	// Projects are set when the configuration file is used instead of
+	// the paths.
+	Projects []*Command
+}
+
+// Contract is a contract schema generated along with the output.
+type Contract struct {
+	gql.Contract
 	Output string
-	Indent string
 }
 
 type Options struct {
@@ -32,21 +64,35 @@ func (c *Command) Check() error {
 		NotEnoughArgs:    "❌ Not enough arguments",
 		OutputFileNeeded: "❌ Output file argument is needed",
 		WrongOption:      "❌ Wrong options",
-		Version:          "v0.2.17",
+		Version:          gql.Version,
 	}
 
 	help := flag.Bool("h", false, "show the help")
 	version := flag.Bool("v", false, "check the version")
 	indent := flag.String("indent", "4s", flagIndentMsg)
+	validate := flag.Bool("validate", false, "validate the merged schema")
+	check := flag.Bool("check", false, "check the output is up to date")
+	watch := flag.Bool("watch", false, "merge again whenever the files change")
+	cacheDir := flag.String("cache-dir", "", flagCacheDirMsg)
+	contracts := contractFlag{}
+	flag.Var(&contracts, "contract", flagContractMsg)
+	include := globFlag{}
+	flag.Var(&include, "include", flagIncludeMsg)
+	exclude := globFlag{}
+	flag.Var(&exclude, "exclude", flagExcludeMsg)
+	ext := flag.String("ext", "", flagExtMsg)
+	config := flag.String("config", "", flagConfigMsg)
+	project := flag.String("project", "", flagProjectMsg)
+	format := flag.String("format", string(gql.FormatSDL), flagFormatMsg)
 
 	flag.Parse()
 
 	if *help {
-		return fmt.Errorf(options.Help)
+		return Message(options.Help)
 	}
 
 	if *version {
-		return fmt.Errorf(options.Version)
+		return Message(options.Version)
 	}
 
 	// indent is never nil, so
@@ -56,6 +102,17 @@ func (c *Command) Check() error {
 	if err != nil {
 		return fmt.Errorf("%s\n%s", err, flagIndentMsg)
 	}
+	if c.Format, err = parseFormat(*format); err != nil {
+		return err
+	}
+	c.Validate = *validate
+	c.CheckOnly = *check
+	c.Watch = *watch
+	c.CacheDir = *cacheDir
+	c.Contracts = contracts
+	if len(include) > 0 || len(exclude) > 0 || *ext != "" {
+		c.Filter = &gql.Filter{Include: include, Exclude: exclude, Extensions: splitList(*ext)}
+	}
 
 	// flag.Parse() remove program's name (aka os.Args[0])
 	// and parsed flags from os.Args, so
@@ -68,29 +125,78 @@ func (c *Command) Check() error {
 
 	// check the number of args
 	if argsCount == 0 {
-		// no arg -> print help msg
-		return fmt.Errorf(options.Help)
+		// no arg -> use the configuration file if any,
+		// print help msg otherwise
+		name := *config
+		if name == "" {
+			if name, err = FindConfig("."); err != nil {
+				return err
+			}
+		}
+		if name == "" && *project != "" {
+			return fmt.Errorf("❌ Not found %s in the working directory or its parents", ConfigName)
+		}
+		if name == "" {
+			return errors.New(options.Help)
+		}
+		return c.loadConfig(name, *project)
+	}
+
+	if *config != "" || *project != "" {
+		return fmt.Errorf("❌ -config and -project cannot be used along with the paths")
 	}
 
 	if argsCount == 1 {
-		return fmt.Errorf(options.OutputFileNeeded)
+		return errors.New(options.OutputFileNeeded)
 	}
 
 	c.Paths = c.Args[:argsCount-1]
 	c.Output = c.Args[argsCount-1]
-
-	// check passed paths is existing.
-	// iter from 1 to argsCount-1 because the last
-	// argument is an output file
-	for _, path := range c.Paths {
-		if _, err = os.Stat(path); os.IsNotExist(err) {
-			return fmt.Errorf(options.PathNotExist, path)
+	if c.CheckOnly && c.Output == Stdout {
+		return fmt.Errorf("❌ -check cannot compare the standard output")
+	}
+	if c.Watch {
+		for _, path := range c.Paths {
+			if path == gql.Stdin {
+				return fmt.Errorf("❌ -watch cannot watch the standard input")
+			}
 		}
 	}
 
+	return checkPaths(c.Paths)
+}
+
+// checkPaths checks the paths exist. The standard input, "-", can be given
+// once.
+func checkPaths(paths []string) error {
+	stdin := false
+	for _, path := range paths {
+		if path == gql.Stdin {
+			if stdin {
+				return fmt.Errorf("❌ The standard input '-' can be given only once")
+			}
+			stdin = true
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("❌ Path '%s' does not Exist", path)
+		}
+	}
 	return nil
 }
 
+// parseFormat checks the format is one of gql.Formats.
+func parseFormat(s string) (gql.Format, error) {
+	names := make([]string, 0, len(gql.Formats))
+	for _, f := range gql.Formats {
+		if gql.Format(s) == f {
+			return f, nil
+		}
+		names = append(names, string(f))
+	}
+	return "", fmt.Errorf(`❌ Unknown format "%s", it should be one of %s`, s, strings.Join(names, ", "))
+}
+
 func convIndent(s string) (string, error) {
 	if s == "" {
 		return "", fmt.Errorf("indent should not be empty")
@@ -127,3 +233,59 @@ func convIndent(s string) (string, error) {
 
 	return strings.Repeat(i, n), nil
 }
+
+// contractFlag collects the repeated -contract flags.
+type contractFlag []Contract
+
+func (f *contractFlag) String() string {
+	return fmt.Sprint(*f)
+}
+
+// Set parses a contract in the form of OUTPUT=+include,-exclude
+func (f *contractFlag) Set(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return fmt.Errorf(`contract should be in the form of OUTPUT=+tag,-tag`)
+	}
+
+	c := Contract{Output: s[:i]}
+	c.Name = strings.TrimSuffix(filepath.Base(c.Output), filepath.Ext(c.Output))
+
+	for _, tag := range strings.Split(s[i+1:], ",") {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case strings.HasPrefix(tag, "+") && len(tag) > 1:
+			c.Include = append(c.Include, tag[1:])
+		case strings.HasPrefix(tag, "-") && len(tag) > 1:
+			c.Exclude = append(c.Exclude, tag[1:])
+		default:
+			return fmt.Errorf(`unknown tag "%s", it should start with + or -`, tag)
+		}
+	}
+
+	*f = append(*f, c)
+	return nil
+}
+
+// globFlag collects the repeated -include and -exclude flags.
+type globFlag []string
+
+func (f *globFlag) String() string {
+	return fmt.Sprint(*f)
+}
+
+func (f *globFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// splitList splits a comma separated list, leaving out the empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,275 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gql "github.com/mununki/gqlmerge/lib"
+)
+
+// ConfigName is the name of the configuration file looked for from the
+// working directory upward.
+const ConfigName = "gqlmerge.json"
+
+// Config is the content of the configuration file. Every project is merged
+// by a single run, unless one is picked with -project.
+type Config struct {
+	Projects map[string]*Project `json:"projects"`
+}
+
+// Project declares the files to merge and how. The globs and paths are
+// relative to the directory of the configuration file. The extensions are
+// ".graphql" and ".gql" when empty.
+type Project struct {
+	Include    []string         `json:"include"`
+	Exclude    []string         `json:"exclude"`
+	Extensions []string         `json:"extensions"`
+	Output     string           `json:"output"`
+	Indent     string           `json:"indent"`
+	Format     string           `json:"format"`
+	Merge      MergeConfig      `json:"merge"`
+	Validate   bool             `json:"validate"`
+	Lint       *LintConfig      `json:"lint"`
+	Contracts  []ContractConfig `json:"contracts"`
+	CacheDir   string           `json:"cacheDir"`
+	// Plugins are commands run after the output is generated, with its
+	// path as the last argument.
+	Plugins []string `json:"plugins"`
+}
+
+type MergeConfig struct {
+	// OnConflict is "error", the default, or "keep-first".
+	OnConflict string `json:"onConflict"`
+}
+
+type LintConfig struct {
+	Rules map[string]string `json:"rules"`
+}
+
+type ContractConfig struct {
+	Output  string   `json:"output"`
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// FindConfig looks for the configuration file in dir and its parents, and
+// returns its path, or an empty string when there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		name := filepath.Join(dir, ConfigName)
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ReadConfig reads and checks the configuration file. Unknown keys are
+// reported, as they are likely typos.
+func ReadConfig(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+
+	config := &Config{}
+	if err := d.Decode(config); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	if len(config.Projects) == 0 {
+		return nil, fmt.Errorf("%s: no projects found", name)
+	}
+	for _, n := range config.ProjectNames() {
+		p := config.Projects[n]
+		if p == nil || p.Output == "" {
+			return nil, fmt.Errorf("%s: the project '%s' needs an output", name, n)
+		}
+		if _, err := parseFormat(p.Format); p.Format != "" && err != nil {
+			return nil, fmt.Errorf("%s: the project '%s': %s", name, n, strings.TrimPrefix(err.Error(), "❌ "))
+		}
+		if _, err := conflictPolicy(p.Merge.OnConflict); err != nil {
+			return nil, fmt.Errorf("%s: the project '%s': %s", name, n, err)
+		}
+		for _, c := range p.Contracts {
+			if c.Output == "" {
+				return nil, fmt.Errorf("%s: the project '%s' has a contract without an output", name, n)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// ProjectNames returns the names of the projects, sorted.
+func (c *Config) ProjectNames() []string {
+	names := make([]string, 0, len(c.Projects))
+	for n := range c.Projects {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadConfig sets the projects of the configuration file as c.Projects.
+// The flags which are set override the values of every project.
+func (c *Command) loadConfig(name, project string) error {
+	config, err := ReadConfig(name)
+	if err != nil {
+		return fmt.Errorf("❌ %s", err)
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	names := config.ProjectNames()
+	if project != "" {
+		if _, ok := config.Projects[project]; !ok {
+			return fmt.Errorf("❌ Project '%s' is not found in %s, it should be one of %s", project, name, strings.Join(names, ", "))
+		}
+		names = []string{project}
+	}
+
+	dir := relDir(filepath.Dir(name))
+	for _, n := range names {
+		pc := config.Projects[n]
+
+		p := &Command{
+			Name:      n,
+			Dir:       dir,
+			Paths:     []string{dir},
+			Output:    outputPath(dir, pc.Output),
+			Indent:    c.Indent,
+			Format:    c.Format,
+			Validate:  c.Validate,
+			CheckOnly: c.CheckOnly,
+			Watch:     c.Watch,
+			CacheDir:  c.CacheDir,
+			Contracts: c.Contracts,
+			Plugins:   pc.Plugins,
+		}
+
+		if pc.Indent != "" && !set["indent"] {
+			if p.Indent, err = convIndent(pc.Indent); err != nil {
+				return fmt.Errorf("❌ %s: the project '%s': %s", name, n, err)
+			}
+		}
+		if pc.Format != "" && !set["format"] {
+			p.Format = gql.Format(pc.Format)
+		}
+		if !set["validate"] {
+			p.Validate = pc.Validate
+		}
+		if pc.CacheDir != "" && !set["cache-dir"] {
+			p.CacheDir = filepath.Join(dir, pc.CacheDir)
+		}
+		if !set["contract"] {
+			p.Contracts = nil
+			for _, cc := range pc.Contracts {
+				out := outputPath(dir, cc.Output)
+				p.Contracts = append(p.Contracts, Contract{
+					Contract: gql.Contract{
+						Name:    strings.TrimSuffix(filepath.Base(out), filepath.Ext(out)),
+						Include: cc.Include,
+						Exclude: cc.Exclude,
+					},
+					Output: out,
+				})
+			}
+		}
+		if pc.Lint != nil {
+			p.Lint = map[string]string{}
+			for r, s := range pc.Lint.Rules {
+				p.Lint[r] = s
+			}
+		}
+		p.OnConflict, _ = conflictPolicy(pc.Merge.OnConflict)
+
+		// the outputs are left out, as they would be merged again
+		// otherwise
+		p.Filter = &gql.Filter{
+			Include:    pc.Include,
+			Exclude:    append([]string{}, pc.Exclude...),
+			Extensions: pc.Extensions,
+		}
+		if c.Filter != nil && set["include"] {
+			p.Filter.Include = c.Filter.Include
+		}
+		if c.Filter != nil && set["exclude"] {
+			p.Filter.Exclude = append([]string{}, c.Filter.Exclude...)
+		}
+		if c.Filter != nil && set["ext"] {
+			p.Filter.Extensions = c.Filter.Extensions
+		}
+		for _, out := range append([]string{pc.Output}, contractOutputs(pc.Contracts)...) {
+			p.Filter.Exclude = append(p.Filter.Exclude, "/"+filepath.ToSlash(filepath.Clean(out)))
+		}
+
+		c.Projects = append(c.Projects, p)
+	}
+
+	return nil
+}
+
+// outputPath returns the path of the output relative to the directory of
+// the configuration file, or the standard output.
+func outputPath(dir, output string) string {
+	if output == Stdout {
+		return output
+	}
+	return filepath.Join(dir, output)
+}
+
+func contractOutputs(cs []ContractConfig) []string {
+	outs := make([]string, 0, len(cs))
+	for _, c := range cs {
+		outs = append(outs, c.Output)
+	}
+	return outs
+}
+
+func conflictPolicy(s string) (gql.ConflictPolicy, error) {
+	switch s {
+	case "", "error":
+		return gql.ConflictError, nil
+	case "keep-first":
+		return gql.ConflictKeepFirst, nil
+	default:
+		return gql.ConflictError, fmt.Errorf(`unknown onConflict "%s", it should be "error" or "keep-first"`, s)
+	}
+}
+
+// relDir returns the directory relative to the working directory when it
+// is below it, so that the printed paths stay short.
+func relDir(dir string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	return rel
+}
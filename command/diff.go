@@ -0,0 +1,32 @@
+package command
+
+import (
+	"errors"
+	"flag"
+)
+
+// Diff for `gqlmerge diff`
+type Diff struct {
+	Args  []string
+	Old   string
+	Paths []string
+}
+
+func (c *Diff) Check() error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, DiffUsage())
+	}
+
+	args := fs.Args()
+	if len(args) < 2 {
+		return errors.New(DiffUsage())
+	}
+
+	c.Old = args[0]
+	c.Paths = args[1:]
+
+	return checkPaths(args)
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	gql "github.com/mununki/gqlmerge/lib"
+)
+
+// Docs for `gqlmerge docs`
+type Docs struct {
+	Args   []string
+	Format gql.DocsFormat
+	Paths  []string
+	Output string
+}
+
+func (c *Docs) Check() error {
+	fs := flag.NewFlagSet("docs", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	format := fs.String("format", string(gql.DocsMarkdown), flagDocsFormatMsg)
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, DocsUsage())
+	}
+
+	switch c.Format = gql.DocsFormat(*format); c.Format {
+	case gql.DocsMarkdown, gql.DocsHTML:
+	default:
+		return fmt.Errorf(`❌ Unknown format "%s", it should be "markdown" or "html"`, *format)
+	}
+
+	args := fs.Args()
+	if len(args) < 2 {
+		return errors.New(DocsUsage())
+	}
+
+	c.Paths = args[:len(args)-1]
+	c.Output = args[len(args)-1]
+	if c.Output == "-" {
+		return fmt.Errorf("❌ The output of the docs should be a directory")
+	}
+
+	return checkPaths(c.Paths)
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Extract for `gqlmerge extract`
+type Extract struct {
+	Args   []string
+	Paths  []string
+	Output string
+	Indent string
+	Fields []string
+}
+
+func (c *Extract) Check() error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	indent := fs.String("indent", "4s", flagIndentMsg)
+	fields := fs.String("fields", "", flagFieldsMsg)
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, ExtractUsage())
+	}
+
+	var err error
+	c.Indent, err = convIndent(*indent)
+	if err != nil {
+		return fmt.Errorf("%s\n%s", err, flagIndentMsg)
+	}
+
+	for _, f := range strings.Split(*fields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			c.Fields = append(c.Fields, f)
+		}
+	}
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("❌ At least one root field is needed\n%s", flagFieldsMsg)
+	}
+
+	args := fs.Args()
+	if len(args) < 2 {
+		return errors.New(ExtractUsage())
+	}
+
+	c.Paths = args[:len(args)-1]
+	c.Output = args[len(args)-1]
+
+	return checkPaths(c.Paths)
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Lint for `gqlmerge lint`
+type Lint struct {
+	Args  []string
+	Paths []string
+	Rules map[string]string
+}
+
+func (c *Lint) Check() error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	c.Rules = map[string]string{}
+	fs.Var(ruleFlag(c.Rules), "rule", flagRuleMsg)
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, LintUsage())
+	}
+
+	c.Paths = fs.Args()
+	if len(c.Paths) == 0 {
+		return errors.New(LintUsage())
+	}
+
+	return checkPaths(c.Paths)
+}
+
+// ruleFlag collects the repeated -rule flags.
+type ruleFlag map[string]string
+
+func (f ruleFlag) String() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
+// Set parses a rule severity in the form of NAME=SEVERITY
+func (f ruleFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf(`rule should be in the form of NAME=error|warning|off`)
+	}
+	f[strings.TrimSpace(s[:i])] = strings.TrimSpace(s[i+1:])
+	return nil
+}
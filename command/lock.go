@@ -0,0 +1,66 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const defaultLockfile = "gqlmerge.lock"
+
+// Lock for `gqlmerge lock`
+type Lock struct {
+	Args     []string
+	Paths    []string
+	Lockfile string
+}
+
+func (c *Lock) Check() error {
+	fs := flag.NewFlagSet("lock", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	fs.StringVar(&c.Lockfile, "lockfile", defaultLockfile, flagLockfileMsg)
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, LockUsage())
+	}
+
+	c.Paths = fs.Args()
+	if len(c.Paths) == 0 {
+		return errors.New(LockUsage())
+	}
+
+	return checkPaths(c.Paths)
+}
+
+// Verify for `gqlmerge verify`
+type Verify struct {
+	Args           []string
+	Paths          []string
+	Lockfile       string
+	AcceptBreaking bool
+}
+
+func (c *Verify) Check() error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	fs.StringVar(&c.Lockfile, "lockfile", defaultLockfile, flagLockfileMsg)
+	fs.BoolVar(&c.AcceptBreaking, "accept-breaking", false, flagAcceptBreakingMsg)
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, VerifyUsage())
+	}
+
+	c.Paths = fs.Args()
+	if len(c.Paths) == 0 {
+		return errors.New(VerifyUsage())
+	}
+
+	if _, err := os.Stat(c.Lockfile); os.IsNotExist(err) {
+		return fmt.Errorf("❌ Lockfile '%s' does not Exist, run 'gqlmerge lock' first", c.Lockfile)
+	}
+
+	return checkPaths(c.Paths)
+}
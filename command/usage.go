@@ -1,22 +1,101 @@
 package command
 
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// Message is returned by Check instead of an error for the help or the
+// version asked for with -h or -v.
+type Message string
+
+func (m Message) Error() string {
+	return string(m)
+}
+
+// parseError returns the error of the flags along with the usage, or the
+// usage as a Message for -h.
+func parseError(err error, usage string) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return Message(usage)
+	}
+	return fmt.Errorf("%s\n%s", err, usage)
+}
+
 func Usage() string {
-	return helpMsg + flagIndentMsg
+	return helpMsg + flagIndentMsg + "\n" + flagContractMsg + "\n" + flagCacheDirMsg + "\n" + flagIncludeMsg + "\n" + flagExcludeMsg + "\n" + flagExtMsg + "\n" + flagConfigMsg + "\n" + flagProjectMsg + "\n" + flagFormatMsg
+}
+
+func ValidateUsage() string {
+	return validateHelpMsg
+}
+
+func LintUsage() string {
+	return lintHelpMsg + flagRuleMsg + "\n"
+}
+
+func DiffUsage() string {
+	return diffHelpMsg
+}
+
+func ChangelogUsage() string {
+	return changelogHelpMsg
+}
+
+func DocsUsage() string {
+	return docsHelpMsg + flagDocsFormatMsg + "\n"
+}
+
+func LockUsage() string {
+	return lockHelpMsg + flagLockfileMsg + "\n"
+}
+
+func VerifyUsage() string {
+	return verifyHelpMsg + flagLockfileMsg + "\n" + flagAcceptBreakingMsg + "\n"
+}
+
+func ExtractUsage() string {
+	return extractHelpMsg + flagFieldsMsg + "\n" + flagIndentMsg
 }
 
 const helpMsg = `👋 'gqlmerge' is the tool to merge & stitch GraphQL files and generate a GraphQL schema
 Author : Woonki Moon <woonki.moon@gmail.com>
 
 Usage:	gqlmerge [FLAG ...] [PATH ...] [OUTPUT]
+	gqlmerge [COMMAND] [FLAG ...] [PATH ...] [OUTPUT]
 
 e.g.
 
 	gqlmerge ./schema schema.graphql
+	cat schema.graphql | gqlmerge - ./schema - | prettier --parser graphql
+
+A "-" path reads the standard input and a "-" output writes the standard
+output. The messages are written to the standard error. A .json path is
+read as an introspection result.
+
+Without any path, the projects of the gqlmerge.json found in the working
+directory or its parents are merged. The flags override its values.
+
+Commands:
+
+	extract	: generates a schema with only the given root fields
+	validate	: validates the merged schema against the GraphQL specification
+	lint	: checks the merged schema against the style rules
+	diff	: compares a schema with the merged one and reports the breaking changes
+	changelog	: generates a Markdown changelog between a schema and the merged one
+	docs	: generates the Markdown or HTML documentation of the merged schema
+	lock	: records the merged schema into a lockfile
+	verify	: checks the merged schema against the lockfile
 
 Flags:
 
 	-v	: check the version
 	-h	: help
+	-validate	: validates the merged schema before generating it
+	-check	: checks the output files are up to date without writing them,
+		  prints the differences and fails when they are not
+	-watch	: merges again whenever a GraphQL file is added, changed or removed
 `
 
 const flagIndentMsg = `
@@ -29,3 +108,162 @@ const flagIndentMsg = `
 
 	If "n" is not stated 1 will be used, 
 	so "--indent=1t" is equal to "--indent=t"`
+
+const flagCacheDirMsg = `
+	-cache-dir	: caches the parsed files in the directory, so that the next runs
+		  parse only the changed files, e.g. -cache-dir=.gqlmerge-cache`
+
+const flagIncludeMsg = `
+	-include	: merges only the files matching the glob, relative to the path,
+		  e.g. -include='**/*.graphql', and can be repeated`
+
+const flagExcludeMsg = `
+	-exclude	: leaves out the files matching the glob, e.g. -exclude=__fixtures__,
+		  and can be repeated. A glob without a slash matches at any depth
+
+	The files and directories listed in a .gqlmergeignore file, in the
+	gitignore syntax, are left out of the directory it is in.`
+
+const flagExtMsg = `
+	-ext	: (default=.graphql,.gql) comma separated extensions of the GraphQL files,
+		  e.g. -ext=.graphql,.graphqls`
+
+const flagConfigMsg = `
+	-config	: the configuration file to use instead of looking for gqlmerge.json`
+
+const flagProjectMsg = `
+	-project	: merges only the project of the configuration file`
+
+const flagFormatMsg = `
+	-format	: (default=sdl) the format of the outputs, "sdl", "ast-json" for the
+		  versioned JSON form of the schema, with the positions and descriptions,
+		  or "introspection" for the result of the introspection query`
+
+const flagContractMsg = `
+	-contract	: generates a contract schema filtered by @tag(name: "...")
+
+	It follows the next pattern: -contract={output}={tags},
+
+		* output - the contract schema file
+		* tags - comma separated tags, "+tag" to include and "-tag" to exclude
+
+	It can be repeated to generate several contracts from a single run,
+	e.g. -contract=public.graphql=-internal -contract=internal.graphql=+internal`
+
+const extractHelpMsg = `👋 'gqlmerge extract' generates a schema which contains only the given root fields
+and every type they need
+
+Usage:	gqlmerge extract -fields [FIELD,...] [FLAG ...] [PATH ...] [OUTPUT]
+
+e.g.
+
+	gqlmerge extract -fields Query.orders,Mutation.createOrder ./schema partner.graphql
+
+Flags:
+`
+
+const flagFieldsMsg = `
+	-fields	: comma separated root fields to extract, e.g. Query.orders`
+
+const validateHelpMsg = `👋 'gqlmerge validate' validates the merged schema against the type system
+validation rules of the GraphQL specification
+
+Usage:	gqlmerge validate [PATH ...]
+
+e.g.
+
+	gqlmerge validate ./schema
+`
+
+const lintHelpMsg = `👋 'gqlmerge lint' checks the merged schema against the style rules
+
+Usage:	gqlmerge lint [FLAG ...] [PATH ...]
+
+e.g.
+
+	gqlmerge lint -rule type-description=warning -rule input-type-suffix=off ./schema
+
+Rules:
+
+	type-pascal-case, field-camel-case, enum-value-screaming-case,
+	type-description, root-field-description, deprecated-reason,
+	input-type-suffix, no-nullable-list-items
+
+A comment "# gqlmerge-lint-disable [RULE, ...]" on or above an element
+suppresses the rules for it.
+
+Flags:
+`
+
+const flagRuleMsg = `
+	-rule	: sets the severity of a rule, error, warning or off
+
+	It follows the next pattern: -rule={name}={severity},
+	and can be repeated, e.g. -rule=type-description=off`
+
+const diffHelpMsg = `👋 'gqlmerge diff' compares the old schema with the merged one and classifies
+every change as breaking, dangerous or safe. It fails when a change is breaking.
+
+Usage:	gqlmerge diff [OLD] [PATH ...]
+
+e.g.
+
+	gqlmerge diff schema.graphql ./schema
+`
+
+const changelogHelpMsg = `👋 'gqlmerge changelog' generates a Markdown changelog of the changes between
+the old schema and the merged one, grouped by type
+
+Usage:	gqlmerge changelog [OLD] [PATH ...] [OUTPUT]
+
+e.g.
+
+	gqlmerge changelog schema.graphql ./schema CHANGES.md
+`
+
+const docsHelpMsg = `👋 'gqlmerge docs' generates the documentation of the merged schema into the
+output directory, an index and a page per type in its 'types' directory
+
+Usage:	gqlmerge docs [FLAG ...] [PATH ...] [OUTPUT]
+
+e.g.
+
+	gqlmerge docs ./schema docs
+	gqlmerge docs -format=html ./schema site
+
+Flags:
+`
+
+const flagDocsFormatMsg = `
+	-format	: (default=markdown) "markdown" for Markdown pages or "html" for a
+		  static site which needs no other file`
+
+const lockHelpMsg = `👋 'gqlmerge lock' records a canonical snapshot of the merged schema and its hash
+into a lockfile
+
+Usage:	gqlmerge lock [FLAG ...] [PATH ...]
+
+e.g.
+
+	gqlmerge lock ./schema
+
+Flags:
+`
+
+const verifyHelpMsg = `👋 'gqlmerge verify' checks the merged schema against the lockfile. It fails when
+the schema differs from the lockfile in a breaking way.
+
+Usage:	gqlmerge verify [FLAG ...] [PATH ...]
+
+e.g.
+
+	gqlmerge verify ./schema
+
+Flags:
+`
+
+const flagLockfileMsg = `
+	-lockfile	: (default=gqlmerge.lock) the lockfile`
+
+const flagAcceptBreakingMsg = `
+	-accept-breaking	: does not fail on the breaking changes`
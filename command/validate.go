@@ -0,0 +1,28 @@
+package command
+
+import (
+	"errors"
+	"flag"
+)
+
+// Validate for `gqlmerge validate`
+type Validate struct {
+	Args  []string
+	Paths []string
+}
+
+func (c *Validate) Check() error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.Usage = func() {}
+
+	if err := fs.Parse(c.Args); err != nil {
+		return parseError(err, ValidateUsage())
+	}
+
+	c.Paths = fs.Args()
+	if len(c.Paths) == 0 {
+		return errors.New(ValidateUsage())
+	}
+
+	return checkPaths(c.Paths)
+}
@@ -0,0 +1,594 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ASTVersion is the version of the JSON form of the schema written by
+// MarshalAST. It changes only when the form changes in an incompatible
+// way, e.g. a renamed or removed key.
+const ASTVersion = 1
+
+// The JSON form of the schema is meant for the tools not written in Go. It
+// does not depend on the Go types of the schema, so that they can change
+// without breaking its readers.
+//
+// The descriptions and comments are kept as written in the sources, e.g.
+// with their quotes, so that the schema is printed the same after a round
+// trip. The text of the description is given along for convenience.
+type astDocument struct {
+	Version              int                       `json:"version"`
+	SchemaDefinitions    []*astSchemaDefinition    `json:"schemaDefinitions"`
+	DirectiveDefinitions []*astDirectiveDefinition `json:"directiveDefinitions"`
+	Types                []*astObject              `json:"types"`
+	Scalars              []*astScalar              `json:"scalars"`
+	Enums                []*astEnum                `json:"enums"`
+	Interfaces           []*astObject              `json:"interfaces"`
+	Unions               []*astUnion               `json:"unions"`
+	Inputs               []*astObject              `json:"inputs"`
+}
+
+// astLoc is the position of a definition in its source.
+type astLoc struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// astDoc holds the description and the comments of a node.
+type astDoc struct {
+	Description  string   `json:"description,omitempty"`
+	Descriptions []string `json:"descriptions,omitempty"`
+	Comments     []string `json:"comments,omitempty"`
+}
+
+type astSchemaDefinition struct {
+	Loc          astLoc  `json:"loc"`
+	Query        *string `json:"query,omitempty"`
+	Mutation     *string `json:"mutation,omitempty"`
+	Subscription *string `json:"subscription,omitempty"`
+	astDoc
+}
+
+type astDirectiveDefinition struct {
+	Loc        astLoc           `json:"loc"`
+	Name       string           `json:"name"`
+	Arguments  []*astInputValue `json:"arguments"`
+	Repeatable bool             `json:"repeatable"`
+	Locations  []string         `json:"locations"`
+	astDoc
+}
+
+// astObject is an object type, an interface or an input object type.
+type astObject struct {
+	Loc        astLoc          `json:"loc"`
+	Name       string          `json:"name"`
+	Extend     bool            `json:"extend,omitempty"`
+	Interfaces []string        `json:"interfaces,omitempty"`
+	Fields     []*astField     `json:"fields"`
+	Directives []*astDirective `json:"directives"`
+	astDoc
+}
+
+type astScalar struct {
+	Loc        astLoc          `json:"loc"`
+	Name       string          `json:"name"`
+	Directives []*astDirective `json:"directives"`
+	astDoc
+}
+
+type astEnum struct {
+	Loc        astLoc          `json:"loc"`
+	Name       string          `json:"name"`
+	Values     []*astEnumValue `json:"values"`
+	Directives []*astDirective `json:"directives"`
+	astDoc
+}
+
+type astEnumValue struct {
+	Loc        astLoc          `json:"loc"`
+	Name       string          `json:"name"`
+	Directives []*astDirective `json:"directives"`
+	astDoc
+}
+
+type astUnion struct {
+	Loc        astLoc          `json:"loc"`
+	Name       string          `json:"name"`
+	Types      []string        `json:"types"`
+	Directives []*astDirective `json:"directives"`
+	astDoc
+}
+
+// astField is a field of an object type or an interface, or an input field
+// with its default value.
+type astField struct {
+	Loc           astLoc           `json:"loc"`
+	Name          string           `json:"name"`
+	Arguments     []*astInputValue `json:"arguments,omitempty"`
+	Type          *astType         `json:"type"`
+	DefaultValues []string         `json:"defaultValues,omitempty"`
+	Directives    []*astDirective  `json:"directives"`
+	astDoc
+}
+
+// astInputValue is an argument of a field or of a directive definition.
+type astInputValue struct {
+	Loc           astLoc          `json:"loc"`
+	Name          string          `json:"name"`
+	Type          *astType        `json:"type"`
+	DefaultValues []string        `json:"defaultValues,omitempty"`
+	Directives    []*astDirective `json:"directives"`
+	astDoc
+}
+
+// astType is a type reference, nested as in the GraphQL.js AST, e.g.
+// [Post!]! is a NonNullType of a ListType of a NonNullType of the
+// NamedType Post.
+type astType struct {
+	Kind string   `json:"kind"`
+	Name string   `json:"name,omitempty"`
+	Type *astType `json:"type,omitempty"`
+}
+
+const (
+	astNamedType   = "NamedType"
+	astListType    = "ListType"
+	astNonNullType = "NonNullType"
+)
+
+type astDirective struct {
+	Loc       astLoc                  `json:"loc"`
+	Name      string                  `json:"name"`
+	Arguments []*astDirectiveArgument `json:"arguments"`
+}
+
+// astDirectiveArgument is the value of an argument, as written in the
+// source, or the items of a list.
+type astDirectiveArgument struct {
+	Name   string   `json:"name"`
+	List   bool     `json:"list,omitempty"`
+	Values []string `json:"values"`
+	astDoc
+}
+
+// MarshalAST returns the schema in its versioned JSON form, with the
+// definitions, their positions, descriptions and comments.
+func MarshalAST(s *Schema) ([]byte, error) {
+	d := &astDocument{
+		Version:              ASTVersion,
+		SchemaDefinitions:    []*astSchemaDefinition{},
+		DirectiveDefinitions: []*astDirectiveDefinition{},
+		Types:                []*astObject{},
+		Scalars:              []*astScalar{},
+		Enums:                []*astEnum{},
+		Interfaces:           []*astObject{},
+		Unions:               []*astUnion{},
+		Inputs:               []*astObject{},
+	}
+
+	for _, sd := range s.SchemaDefinitions {
+		d.SchemaDefinitions = append(d.SchemaDefinitions, &astSchemaDefinition{
+			Loc:          loc(sd.BaseFileInfo),
+			Query:        sd.Query,
+			Mutation:     sd.Mutation,
+			Subscription: sd.Subscription,
+			astDoc:       doc(sd.Descriptions, nil),
+		})
+	}
+	for _, dd := range s.DirectiveDefinitions {
+		d.DirectiveDefinitions = append(d.DirectiveDefinitions, &astDirectiveDefinition{
+			Loc:        loc(dd.BaseFileInfo),
+			Name:       dd.Name,
+			Arguments:  astArgs(dd.Args),
+			Repeatable: dd.Repeatable,
+			Locations:  nonNil(dd.Locations),
+			astDoc:     doc(dd.Descriptions, nil),
+		})
+	}
+	for _, t := range s.Types {
+		d.Types = append(d.Types, &astObject{
+			Loc:        loc(t.BaseFileInfo),
+			Name:       t.Name,
+			Extend:     t.Extend,
+			Interfaces: t.ImplTypes,
+			Fields:     astFields(t.Fields),
+			Directives: astDirectives(t.Directives),
+			astDoc:     doc(t.Descriptions, nil),
+		})
+	}
+	for _, sc := range s.Scalars {
+		d.Scalars = append(d.Scalars, &astScalar{
+			Loc:        loc(sc.BaseFileInfo),
+			Name:       sc.Name,
+			Directives: astDirectives(sc.Directives),
+			astDoc:     doc(sc.Descriptions, sc.Comments),
+		})
+	}
+	for _, e := range s.Enums {
+		ae := &astEnum{
+			Loc:        loc(e.BaseFileInfo),
+			Name:       e.Name,
+			Values:     []*astEnumValue{},
+			Directives: astDirectives(e.Directives),
+			astDoc:     doc(e.Descriptions, nil),
+		}
+		for _, v := range e.EnumValues {
+			ae.Values = append(ae.Values, &astEnumValue{
+				Loc:        loc(v.BaseFileInfo),
+				Name:       v.Name,
+				Directives: astDirectives(v.Directives),
+				astDoc:     doc(v.Descriptions, v.Comments),
+			})
+		}
+		d.Enums = append(d.Enums, ae)
+	}
+	for _, i := range s.Interfaces {
+		d.Interfaces = append(d.Interfaces, &astObject{
+			Loc:        loc(i.BaseFileInfo),
+			Name:       i.Name,
+			Fields:     astFields(i.Fields),
+			Directives: astDirectives(i.Directives),
+			astDoc:     doc(i.Descriptions, nil),
+		})
+	}
+	for _, u := range s.Unions {
+		d.Unions = append(d.Unions, &astUnion{
+			Loc:        loc(u.BaseFileInfo),
+			Name:       u.Name,
+			Types:      nonNil(u.Types),
+			Directives: astDirectives(u.Directives),
+			astDoc:     doc(u.Descriptions, nil),
+		})
+	}
+	for _, i := range s.Inputs {
+		d.Inputs = append(d.Inputs, &astObject{
+			Loc:        loc(i.BaseFileInfo),
+			Name:       i.Name,
+			Fields:     astFields(i.Fields),
+			Directives: astDirectives(i.Directives),
+			astDoc:     doc(i.Descriptions, nil),
+		})
+	}
+
+	bs, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bs, '\n'), nil
+}
+
+// UnmarshalAST reads the schema from its JSON form written by MarshalAST.
+// The schema is printed the same as the one which was written.
+func UnmarshalAST(data []byte) (*Schema, error) {
+	d := &astDocument{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("invalid schema AST: %s", err)
+	}
+	if d.Version != ASTVersion {
+		return nil, fmt.Errorf("unsupported version %d of schema AST, it should be %d", d.Version, ASTVersion)
+	}
+
+	s, err := d.schema()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema AST: %s", err)
+	}
+	return s, nil
+}
+
+// schema converts the document into a schema. The type references which
+// the schema cannot hold, e.g. lists of lists, are errors.
+func (d *astDocument) schema() (s *Schema, err error) {
+	defer recoverError(&err)
+
+	s = &Schema{}
+	for _, sd := range d.SchemaDefinitions {
+		s.SchemaDefinitions = append(s.SchemaDefinitions, &SchemaDefinition{
+			BaseFileInfo: sd.Loc.fileInfo(),
+			Query:        sd.Query,
+			Mutation:     sd.Mutation,
+			Subscription: sd.Subscription,
+			Descriptions: sd.descriptions(),
+		})
+	}
+	for _, dd := range d.DirectiveDefinitions {
+		s.DirectiveDefinitions = append(s.DirectiveDefinitions, &DirectiveDefinition{
+			BaseFileInfo: dd.Loc.fileInfo(),
+			Name:         dd.Name,
+			Args:         schemaArgs(dd.Arguments),
+			Repeatable:   dd.Repeatable,
+			Locations:    dd.Locations,
+			Descriptions: dd.descriptions(),
+		})
+	}
+	for _, t := range d.Types {
+		s.Types = append(s.Types, &Type{
+			BaseFileInfo: t.Loc.fileInfo(),
+			Name:         t.Name,
+			Impl:         len(t.Interfaces) > 0,
+			ImplTypes:    t.Interfaces,
+			Fields:       schemaFields(t.Fields),
+			Directives:   schemaDirectives(t.Directives),
+			Descriptions: t.descriptions(),
+			Extend:       t.Extend,
+		})
+	}
+	for _, sc := range d.Scalars {
+		s.Scalars = append(s.Scalars, &Scalar{
+			BaseFileInfo: sc.Loc.fileInfo(),
+			Name:         sc.Name,
+			Directives:   schemaDirectives(sc.Directives),
+			Descriptions: sc.descriptions(),
+			Comments:     sc.comments(),
+		})
+	}
+	for _, e := range d.Enums {
+		se := &Enum{
+			BaseFileInfo: e.Loc.fileInfo(),
+			Name:         e.Name,
+			EnumValues:   []EnumValue{},
+			Directives:   schemaDirectives(e.Directives),
+			Descriptions: e.descriptions(),
+		}
+		for _, v := range e.Values {
+			se.EnumValues = append(se.EnumValues, EnumValue{
+				BaseFileInfo: v.Loc.fileInfo(),
+				Name:         v.Name,
+				Directives:   schemaDirectives(v.Directives),
+				Descriptions: v.descriptions(),
+				Comments:     v.comments(),
+			})
+		}
+		s.Enums = append(s.Enums, se)
+	}
+	for _, i := range d.Interfaces {
+		s.Interfaces = append(s.Interfaces, &Interface{
+			BaseFileInfo: i.Loc.fileInfo(),
+			Name:         i.Name,
+			Fields:       schemaFields(i.Fields),
+			Directives:   schemaDirectives(i.Directives),
+			Descriptions: i.descriptions(),
+		})
+	}
+	for _, u := range d.Unions {
+		s.Unions = append(s.Unions, &Union{
+			BaseFileInfo: u.Loc.fileInfo(),
+			Name:         u.Name,
+			Types:        u.Types,
+			Directives:   schemaDirectives(u.Directives),
+			Descriptions: u.descriptions(),
+		})
+	}
+	for _, i := range d.Inputs {
+		s.Inputs = append(s.Inputs, &Input{
+			BaseFileInfo: i.Loc.fileInfo(),
+			Name:         i.Name,
+			Fields:       schemaFields(i.Fields),
+			Directives:   schemaDirectives(i.Directives),
+			Descriptions: i.descriptions(),
+		})
+	}
+	return s, nil
+}
+
+// loc returns the position with the path relative to the working
+// directory, as in the errors.
+func loc(fi BaseFileInfo) astLoc {
+	name := fi.Filename
+	if rel, err := GetRelPath(name); err == nil {
+		name = *rel
+	}
+	return astLoc{File: name, Line: fi.Line, Column: fi.Column}
+}
+
+func (l astLoc) fileInfo() BaseFileInfo {
+	return BaseFileInfo{Filename: l.File, Line: l.Line, Column: l.Column}
+}
+
+func doc(descriptions, comments *[]string) astDoc {
+	d := astDoc{Description: descriptionText(descriptionString(descriptions))}
+	if descriptions != nil {
+		d.Descriptions = *descriptions
+	}
+	if comments != nil {
+		d.Comments = *comments
+	}
+	return d
+}
+
+// descriptions returns the descriptions as written in the source, or the
+// description text written as a string when only the text is given.
+func (d astDoc) descriptions() *[]string {
+	if len(d.Descriptions) > 0 {
+		ds := d.Descriptions
+		return &ds
+	}
+	if d.Description != "" {
+		return descriptions(d.Description)
+	}
+	return &[]string{}
+}
+
+func (d astDoc) comments() *[]string {
+	if len(d.Comments) == 0 {
+		return nil
+	}
+	cs := d.Comments
+	return &cs
+}
+
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+func astFields(fs []*Field) []*astField {
+	afs := make([]*astField, 0, len(fs))
+	for _, f := range fs {
+		af := &astField{
+			Loc:        loc(f.BaseFileInfo),
+			Name:       f.Name,
+			Arguments:  astArgs(f.Args),
+			Type:       astTypeRef(fieldTypeRef(f)),
+			Directives: astDirectives(f.Directives),
+			astDoc:     doc(f.Descriptions, f.Comments),
+		}
+		if f.DefaultValues != nil {
+			af.DefaultValues = *f.DefaultValues
+		}
+		afs = append(afs, af)
+	}
+	return afs
+}
+
+func astArgs(as []*Arg) []*astInputValue {
+	aas := make([]*astInputValue, 0, len(as))
+	for _, a := range as {
+		aa := &astInputValue{
+			Loc:        loc(a.BaseFileInfo),
+			Name:       a.Name,
+			Type:       astTypeRef(argTypeRef(a)),
+			Directives: astDirectives(a.Directives),
+			astDoc:     doc(a.Descriptions, nil),
+		}
+		if a.DefaultValues != nil {
+			aa.DefaultValues = *a.DefaultValues
+		}
+		aas = append(aas, aa)
+	}
+	return aas
+}
+
+func astDirectives(ds []*Directive) []*astDirective {
+	ads := make([]*astDirective, 0, len(ds))
+	for _, d := range ds {
+		ad := &astDirective{
+			Loc:       loc(d.BaseFileInfo),
+			Name:      d.Name,
+			Arguments: []*astDirectiveArgument{},
+		}
+		for _, a := range d.DirectiveArgs {
+			ad.Arguments = append(ad.Arguments, &astDirectiveArgument{
+				Name:   a.Name,
+				List:   a.IsList,
+				Values: nonNil(a.Value),
+				astDoc: doc(a.Descriptions, nil),
+			})
+		}
+		ads = append(ads, ad)
+	}
+	return ads
+}
+
+func astTypeRef(t typeRef) *astType {
+	at := &astType{Kind: astNamedType, Name: t.Name}
+	if !t.Null {
+		at = &astType{Kind: astNonNullType, Type: at}
+	}
+	if t.IsList {
+		at = &astType{Kind: astListType, Type: at}
+		if !t.ListNull {
+			at = &astType{Kind: astNonNullType, Type: at}
+		}
+	}
+	return at
+}
+
+// typeRef returns the type reference, with a single level of list as in
+// the schema.
+func (at *astType) typeRef() typeRef {
+	t := typeRef{Null: true, ListNull: true}
+	if at != nil && at.Kind == astNonNullType && at.Type != nil && at.Type.Kind == astListType {
+		t.ListNull = false
+		at = at.Type
+	}
+	if at != nil && at.Kind == astListType {
+		t.IsList = true
+		at = at.Type
+	}
+	if at != nil && at.Kind == astNonNullType {
+		t.Null = false
+		at = at.Type
+	}
+	if at == nil || at.Kind != astNamedType || at.Name == "" {
+		errorf("unsupported type reference")
+	}
+	t.Name = at.Name
+	if !t.IsList {
+		t.ListNull = false
+	}
+	return t
+}
+
+func schemaFields(afs []*astField) []*Field {
+	fs := make([]*Field, 0, len(afs))
+	for _, af := range afs {
+		t := af.Type.typeRef()
+		f := &Field{
+			BaseFileInfo: af.Loc.fileInfo(),
+			Name:         af.Name,
+			Args:         schemaArgs(af.Arguments),
+			Type:         t.Name,
+			Null:         t.Null,
+			IsList:       t.IsList,
+			IsListNull:   t.ListNull,
+			Directives:   schemaDirectives(af.Directives),
+			Descriptions: af.descriptions(),
+			Comments:     af.comments(),
+		}
+		if af.DefaultValues != nil {
+			vs := af.DefaultValues
+			f.DefaultValues = &vs
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func schemaArgs(aas []*astInputValue) []*Arg {
+	as := make([]*Arg, 0, len(aas))
+	for _, aa := range aas {
+		t := aa.Type.typeRef()
+		a := &Arg{
+			BaseFileInfo: aa.Loc.fileInfo(),
+			Name:         aa.Name,
+			Type:         t.Name,
+			Null:         t.Null,
+			IsList:       t.IsList,
+			IsListNull:   t.ListNull,
+			Directives:   schemaDirectives(aa.Directives),
+			Descriptions: aa.descriptions(),
+		}
+		if aa.DefaultValues != nil {
+			vs := aa.DefaultValues
+			a.DefaultValues = &vs
+		}
+		as = append(as, a)
+	}
+	return as
+}
+
+func schemaDirectives(ads []*astDirective) []*Directive {
+	ds := make([]*Directive, 0, len(ads))
+	for _, ad := range ads {
+		d := &Directive{
+			BaseFileInfo:  ad.Loc.fileInfo(),
+			Name:          ad.Name,
+			DirectiveArgs: []*DirectiveArg{},
+			Descriptions:  &[]string{},
+		}
+		for _, a := range ad.Arguments {
+			d.DirectiveArgs = append(d.DirectiveArgs, &DirectiveArg{
+				Name:         a.Name,
+				Value:        a.Values,
+				IsList:       a.List,
+				Descriptions: a.descriptions(),
+			})
+		}
+		ds = append(ds, d)
+	}
+	return ds
+}
@@ -0,0 +1,102 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestASTRoundTrip(t *testing.T) {
+	for _, dir := range []string{"basic", "arg_input", "default_value", "directives", "object_extension", "property_type"} {
+		s, _, err := MergeSchema("../test/" + dir + "/schema")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bs, err := MarshalAST(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := UnmarshalAST(bs)
+		if err != nil {
+			t.Fatalf("%s: %s", dir, err)
+		}
+
+		if again, _ := MarshalAST(decoded); string(again) != string(bs) {
+			t.Errorf("%s: unexpected AST after a round trip\n%s", dir, again)
+		}
+		opts := PrintOptions{Indent: "  "}
+		if expected, got := printSchema(t, s, opts), printSchema(t, decoded, opts); got != expected {
+			t.Errorf("%s: unexpected schema after a round trip\n%s\nexpected\n%s", dir, got, expected)
+		}
+	}
+}
+
+func TestMarshalAST(t *testing.T) {
+	s := &Schema{}
+	s.Parse(NewParser(strings.NewReader(`"""
+The users
+"""
+type Query {
+  users(first: Int = 10): [User!]! @deprecated(reason: "Use search")
+}
+
+type User {
+  id: ID!
+}`), "query.graphql"))
+
+	out := printSchema(t, s, PrintOptions{Format: FormatASTJSON})
+
+	var d struct {
+		Version int
+		Types   []struct {
+			Loc         struct{ File string }
+			Description string
+			Fields      []struct {
+				Name       string
+				Type       json.RawMessage
+				Arguments  []struct{ DefaultValues []string }
+				Directives []struct {
+					Name      string
+					Arguments []struct{ Values []string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != ASTVersion || len(d.Types) != 2 || d.Types[0].Description != "The users" || d.Types[0].Loc.File != "query.graphql" {
+		t.Fatalf("unexpected AST\n%s", out)
+	}
+
+	f := d.Types[0].Fields[0]
+	expected := `{"kind":"NonNullType","type":{"kind":"ListType","type":{"kind":"NonNullType","type":{"kind":"NamedType","name":"User"}}}}`
+	if compact, _ := json.Marshal(f.Type); string(compact) != expected {
+		t.Errorf("unexpected type %s", compact)
+	}
+	if f.Arguments[0].DefaultValues[0] != "10" || f.Directives[0].Arguments[0].Values[0] != `"Use search"` {
+		t.Errorf("unexpected field\n%s", out)
+	}
+}
+
+func TestUnmarshalAST(t *testing.T) {
+	// only the text of a description is enough
+	s, err := UnmarshalAST([]byte(`{"version": 1, "scalars": [{"name": "Date", "description": "A day"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := strings.TrimSpace(printSchema(t, s, PrintOptions{})); out != "\"A day\"\nscalar Date" {
+		t.Errorf("unexpected schema %q", out)
+	}
+
+	for _, data := range []string{
+		`{"version": 2}`,
+		`{"version": 1, "types": {}}`,
+		`{"version": 1, "types": [{"name": "Query", "fields": [{"name": "grid", "type": {"kind": "ListType", "type": {"kind": "ListType", "type": {"kind": "NamedType", "name": "Int"}}}}]}]}`,
+	} {
+		if _, err := UnmarshalAST([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaBuilder builds a schema in Go, e.g. the types of the tables of a
+// database, to be merged with the files by MergeSchemas and printed like
+// any merged schema.
+//
+//	b := gql.NewSchemaBuilder("tables.go")
+//	b.Type(gql.NewType("User").
+//		Field(gql.NewField("id", "ID!")).
+//		Field(gql.NewField("posts", "[Post!]!").Arg(gql.NewArg("first", "Int").Default("10"))))
+type SchemaBuilder struct {
+	name   string
+	schema *Schema
+}
+
+// NewSchemaBuilder returns a builder of an empty schema. The name stands
+// for the file name in the positions of the errors, e.g. of a conflict.
+func NewSchemaBuilder(name string) *SchemaBuilder {
+	return &SchemaBuilder{name: name, schema: &Schema{}}
+}
+
+func (b *SchemaBuilder) Type(ts ...*TypeBuilder) *SchemaBuilder {
+	for _, t := range ts {
+		t.t.Filename = b.name
+		b.fields(t.t.Fields)
+		b.schema.Types = append(b.schema.Types, t.t)
+	}
+	return b
+}
+
+func (b *SchemaBuilder) Input(is ...*InputBuilder) *SchemaBuilder {
+	for _, i := range is {
+		i.i.Filename = b.name
+		b.fields(i.i.Fields)
+		b.schema.Inputs = append(b.schema.Inputs, i.i)
+	}
+	return b
+}
+
+func (b *SchemaBuilder) Enum(es ...*EnumBuilder) *SchemaBuilder {
+	for _, e := range es {
+		e.e.Filename = b.name
+		for i := range e.e.EnumValues {
+			e.e.EnumValues[i].Filename = b.name
+		}
+		b.schema.Enums = append(b.schema.Enums, e.e)
+	}
+	return b
+}
+
+func (b *SchemaBuilder) fields(fs []*Field) {
+	for _, f := range fs {
+		f.Filename = b.name
+		for _, a := range f.Args {
+			a.Filename = b.name
+		}
+	}
+}
+
+// Schema returns the built schema.
+func (b *SchemaBuilder) Schema() *Schema {
+	return b.schema
+}
+
+// MergeSchemas merges the schemas, e.g. the ones returned by MergeSchema
+// and by a SchemaBuilder, with the same rules as the files. The given
+// schemas are left untouched. It returns nil when there is no schema.
+func MergeSchemas(schemas ...*Schema) (merged *Schema, err error) {
+	defer recoverError(&err)
+
+	ss := make([]Schema, 0, len(schemas))
+	for _, s := range schemas {
+		if s != nil {
+			ss = append(ss, *s.clone())
+		}
+	}
+	if len(ss) == 0 {
+		return nil, nil
+	}
+	return mergeSchemas(ss, ConflictError), nil
+}
+
+// TypeBuilder builds an object type.
+type TypeBuilder struct {
+	t *Type
+}
+
+func NewType(name string) *TypeBuilder {
+	return &TypeBuilder{t: &Type{
+		Name:         name,
+		Fields:       []*Field{},
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *TypeBuilder) Description(d string) *TypeBuilder {
+	b.t.Descriptions = descriptions(d)
+	return b
+}
+
+func (b *TypeBuilder) Implements(interfaces ...string) *TypeBuilder {
+	b.t.Impl = true
+	b.t.ImplTypes = append(b.t.ImplTypes, interfaces...)
+	return b
+}
+
+func (b *TypeBuilder) Field(fs ...*FieldBuilder) *TypeBuilder {
+	for _, f := range fs {
+		b.t.Fields = append(b.t.Fields, f.f)
+	}
+	return b
+}
+
+func (b *TypeBuilder) Directive(ds ...*DirectiveBuilder) *TypeBuilder {
+	b.t.Directives = appendDirectives(b.t.Directives, ds)
+	return b
+}
+
+func (b *TypeBuilder) Build() *Type {
+	return b.t
+}
+
+// InputBuilder builds an input object type.
+type InputBuilder struct {
+	i *Input
+}
+
+func NewInput(name string) *InputBuilder {
+	return &InputBuilder{i: &Input{
+		Name:         name,
+		Fields:       []*Field{},
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *InputBuilder) Description(d string) *InputBuilder {
+	b.i.Descriptions = descriptions(d)
+	return b
+}
+
+// Field adds input fields, which can have a default value but no
+// arguments.
+func (b *InputBuilder) Field(fs ...*FieldBuilder) *InputBuilder {
+	for _, f := range fs {
+		b.i.Fields = append(b.i.Fields, f.f)
+	}
+	return b
+}
+
+func (b *InputBuilder) Directive(ds ...*DirectiveBuilder) *InputBuilder {
+	b.i.Directives = appendDirectives(b.i.Directives, ds)
+	return b
+}
+
+func (b *InputBuilder) Build() *Input {
+	return b.i
+}
+
+// EnumBuilder builds an enum type.
+type EnumBuilder struct {
+	e *Enum
+}
+
+func NewEnum(name string) *EnumBuilder {
+	return &EnumBuilder{e: &Enum{
+		Name:         name,
+		EnumValues:   []EnumValue{},
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *EnumBuilder) Description(d string) *EnumBuilder {
+	b.e.Descriptions = descriptions(d)
+	return b
+}
+
+// Values adds values without description nor directive.
+func (b *EnumBuilder) Values(names ...string) *EnumBuilder {
+	for _, n := range names {
+		b.Value(NewEnumValue(n))
+	}
+	return b
+}
+
+func (b *EnumBuilder) Value(vs ...*EnumValueBuilder) *EnumBuilder {
+	for _, v := range vs {
+		b.e.EnumValues = append(b.e.EnumValues, *v.v)
+	}
+	return b
+}
+
+func (b *EnumBuilder) Directive(ds ...*DirectiveBuilder) *EnumBuilder {
+	b.e.Directives = appendDirectives(b.e.Directives, ds)
+	return b
+}
+
+func (b *EnumBuilder) Build() *Enum {
+	return b.e
+}
+
+// EnumValueBuilder builds a value of an enum type.
+type EnumValueBuilder struct {
+	v *EnumValue
+}
+
+func NewEnumValue(name string) *EnumValueBuilder {
+	return &EnumValueBuilder{v: &EnumValue{
+		Name:         name,
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *EnumValueBuilder) Description(d string) *EnumValueBuilder {
+	b.v.Descriptions = descriptions(d)
+	return b
+}
+
+func (b *EnumValueBuilder) Deprecated(reason string) *EnumValueBuilder {
+	return b.Directive(deprecated(reason))
+}
+
+func (b *EnumValueBuilder) Directive(ds ...*DirectiveBuilder) *EnumValueBuilder {
+	b.v.Directives = appendDirectives(b.v.Directives, ds)
+	return b
+}
+
+// FieldBuilder builds a field of an object type or of an input object
+// type.
+type FieldBuilder struct {
+	f *Field
+}
+
+// NewField returns the builder of a field of the type written as in the
+// schema, e.g. "[Post!]!". It panics when the type is not valid.
+func NewField(name, typ string) *FieldBuilder {
+	t := parseTypeRef(typ)
+	return &FieldBuilder{f: &Field{
+		Name:         name,
+		Args:         []*Arg{},
+		Type:         t.Name,
+		Null:         t.Null,
+		IsList:       t.IsList,
+		IsListNull:   t.ListNull,
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *FieldBuilder) Description(d string) *FieldBuilder {
+	b.f.Descriptions = descriptions(d)
+	return b
+}
+
+func (b *FieldBuilder) Arg(as ...*ArgBuilder) *FieldBuilder {
+	for _, a := range as {
+		b.f.Args = append(b.f.Args, a.a)
+	}
+	return b
+}
+
+// Default sets the default value of an input field, written as in the
+// schema, e.g. `"name"` or `[ADMIN, USER]`. It panics when the value is not
+// a valid literal.
+func (b *FieldBuilder) Default(value string) *FieldBuilder {
+	b.f.DefaultValues = defaultValues(value)
+	return b
+}
+
+func (b *FieldBuilder) Deprecated(reason string) *FieldBuilder {
+	return b.Directive(deprecated(reason))
+}
+
+func (b *FieldBuilder) Directive(ds ...*DirectiveBuilder) *FieldBuilder {
+	b.f.Directives = appendDirectives(b.f.Directives, ds)
+	return b
+}
+
+func (b *FieldBuilder) Build() *Field {
+	return b.f
+}
+
+// ArgBuilder builds an argument of a field.
+type ArgBuilder struct {
+	a *Arg
+}
+
+// NewArg returns the builder of an argument of the type written as in the
+// schema, e.g. "Int!". It panics when the type is not valid.
+func NewArg(name, typ string) *ArgBuilder {
+	t := parseTypeRef(typ)
+	return &ArgBuilder{a: &Arg{
+		Name:         name,
+		Type:         t.Name,
+		Null:         t.Null,
+		IsList:       t.IsList,
+		IsListNull:   t.ListNull,
+		Directives:   []*Directive{},
+		Descriptions: &[]string{},
+	}}
+}
+
+func (b *ArgBuilder) Description(d string) *ArgBuilder {
+	b.a.Descriptions = descriptions(d)
+	return b
+}
+
+// Default sets the default value written as in the schema, e.g. `"name"`
+// or `[1, 2]`. It panics when the value is not a valid literal.
+func (b *ArgBuilder) Default(value string) *ArgBuilder {
+	b.a.DefaultValues = defaultValues(value)
+	return b
+}
+
+func (b *ArgBuilder) Deprecated(reason string) *ArgBuilder {
+	return b.Directive(deprecated(reason))
+}
+
+func (b *ArgBuilder) Directive(ds ...*DirectiveBuilder) *ArgBuilder {
+	b.a.Directives = appendDirectives(b.a.Directives, ds)
+	return b
+}
+
+func (b *ArgBuilder) Build() *Arg {
+	return b.a
+}
+
+// DirectiveBuilder builds an applied directive, e.g. @key(fields: "id").
+type DirectiveBuilder struct {
+	d *Directive
+}
+
+func NewDirective(name string) *DirectiveBuilder {
+	return &DirectiveBuilder{d: &Directive{
+		Name:          strings.TrimPrefix(name, "@"),
+		DirectiveArgs: []*DirectiveArg{},
+		Descriptions:  &[]string{},
+	}}
+}
+
+// Arg adds an argument of the value written as in the schema, e.g. `"id"`
+// or `ADMIN`.
+func (b *DirectiveBuilder) Arg(name, value string) *DirectiveBuilder {
+	b.d.DirectiveArgs = append(b.d.DirectiveArgs, &DirectiveArg{
+		Name:         name,
+		Value:        []string{value},
+		Descriptions: &[]string{},
+	})
+	return b
+}
+
+// ListArg adds an argument of a list of the values written as in the
+// schema.
+func (b *DirectiveBuilder) ListArg(name string, values ...string) *DirectiveBuilder {
+	b.d.DirectiveArgs = append(b.d.DirectiveArgs, &DirectiveArg{
+		Name:         name,
+		Value:        values,
+		IsList:       true,
+		Descriptions: &[]string{},
+	})
+	return b
+}
+
+func (b *DirectiveBuilder) Build() *Directive {
+	return b.d
+}
+
+func appendDirectives(ds []*Directive, bs []*DirectiveBuilder) []*Directive {
+	for _, b := range bs {
+		ds = append(ds, b.d)
+	}
+	return ds
+}
+
+func deprecated(reason string) *DirectiveBuilder {
+	d := NewDirective("deprecated")
+	if reason != "" {
+		d.Arg("reason", quote(reason))
+	}
+	return d
+}
+
+// descriptions returns the description written as in the schema, as a
+// block string when it spans several lines.
+func descriptions(d string) *[]string {
+	if strings.Contains(d, "\n") {
+		return &[]string{`"""` + "\n" + strings.ReplaceAll(d, `"""`, `\"""`) + "\n" + `"""`}
+	}
+	return &[]string{quote(d)}
+}
+
+// quote returns the string value written as a GraphQL string, escaping the
+// quotes, the backslashes and the control characters as the specification
+// allows.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// defaultValues returns the default value as it is stored by the parser,
+// the literal written in the schema. It panics when the value is not a
+// single valid literal.
+func defaultValues(value string) *[]string {
+	p := NewParser(strings.NewReader(value), "")
+	if p.lex.peek() == EofRune {
+		errorf("invalid default value %q", value)
+	}
+	lit := p.parseValueLiteral()
+	if p.lex.peek() != EofRune {
+		errorf("invalid default value %q", value)
+	}
+	return &[]string{lit}
+}
+
+// parseTypeRef parses a type reference such as [User!]!, with a single
+// level of list as in the model.
+func parseTypeRef(s string) typeRef {
+	t := typeRef{Null: true}
+	r := strings.TrimSpace(s)
+	if strings.HasPrefix(r, "[") {
+		t.IsList = true
+		t.ListNull = true
+		if strings.HasSuffix(r, "!") {
+			t.ListNull = false
+			r = strings.TrimSuffix(r, "!")
+		}
+		if !strings.HasSuffix(r, "]") {
+			errorf("invalid type %q", s)
+		}
+		r = r[1 : len(r)-1]
+	}
+	if strings.HasSuffix(r, "!") {
+		t.Null = false
+		r = strings.TrimSuffix(r, "!")
+	}
+	if r == "" || strings.ContainsAny(r, "[]! ") {
+		errorf("invalid type %q", s)
+	}
+	t.Name = r
+	return t
+}
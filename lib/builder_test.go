@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	dir := t.TempDir()
+	src := "type Query {\n  user(id: ID!): User\n}\n\nenum Role {\n  ADMIN\n  USER\n}"
+	if err := os.WriteFile(filepath.Join(dir, "query.graphql"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, _, err := MergeSchema(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewSchemaBuilder("tables.go")
+	b.Type(NewType("User").
+		Description("A user").
+		Directive(NewDirective("key").Arg("fields", `"id"`)).
+		Field(
+			NewField("id", "ID!"),
+			NewField("posts", "[Post!]!").Arg(NewArg("first", "Int").Default("10")),
+			NewField("login", "String").Deprecated("Use id"),
+		))
+	b.Type(NewType("Post").Field(NewField("title", "String!")))
+	b.Type(NewType("Query").Field(NewField("users", "[User!]!")))
+	b.Input(NewInput("UserFilter").Field(NewField("roles", "[Role!]").Default("[ADMIN, USER]")))
+	b.Enum(NewEnum("Role").Values("ADMIN", "USER"))
+
+	merged, err := MergeSchemas(files, b.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds := UndefinedTypes(merged); len(ds) != 0 {
+		t.Errorf("unexpected undefined types %v", ds)
+	}
+
+	out := printSchema(t, merged, PrintOptions{Indent: "  "})
+	for _, s := range []string{
+		"\"A user\"\ntype User @key(fields: \"id\") {",
+		"  posts(first: Int = 10): [Post!]!",
+		"  login: String @deprecated(reason: \"Use id\")",
+		"  roles: [Role!] = [ADMIN, USER]",
+		"  user(id: ID!): User\n  users: [User!]!",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in\n%s", s, out)
+		}
+	}
+	if strings.Count(out, "enum Role") != 1 {
+		t.Errorf("expected the enums to be merged\n%s", out)
+	}
+
+	conflict := NewSchemaBuilder("conflict.go").Enum(NewEnum("Role").Values("GUEST")).Schema()
+	if _, err := MergeSchemas(files, conflict); err == nil || !strings.Contains(err.Error(), "conflict.go") {
+		t.Errorf("expected a conflict in conflict.go, got %v", err)
+	}
+}
+
+func TestParseTypeRef(t *testing.T) {
+	for ref, expected := range map[string]typeRef{
+		"String":   {Name: "String", Null: true},
+		"ID!":      {Name: "ID"},
+		"[Post]":   {Name: "Post", Null: true, IsList: true, ListNull: true},
+		"[Post!]!": {Name: "Post", IsList: true},
+		" [Int]! ": {Name: "Int", Null: true, IsList: true},
+	} {
+		if got := parseTypeRef(ref); got != expected {
+			t.Errorf("%q: expected %+v, got %+v", ref, expected, got)
+		}
+	}
+
+	for _, ref := range []string{"", "[Post", "Post]", "[[Post]]", "Post!!"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected an invalid type", ref)
+				}
+			}()
+			parseTypeRef(ref)
+		}()
+	}
+}
+
+func TestBuilderQuoteAndDefault(t *testing.T) {
+	for s, expected := range map[string]string{
+		`plain`:       `"plain"`,
+		`say "hi"`:    `"say \"hi\""`,
+		`C:\dir`:      `"C:\\dir"`,
+		"tab\there":   `"tab\u0009here"`,
+		"line\nbreak": `"line\nbreak"`,
+		"unicode é ✓": `"unicode é ✓"`,
+	} {
+		if got := quote(s); got != expected {
+			t.Errorf("%q: expected %s, got %s", s, expected, got)
+		}
+	}
+
+	if f := NewField("roles", "[Role!]").Default("[ADMIN, USER]"); (*f.f.DefaultValues)[0] != "[ADMIN, USER]" {
+		t.Errorf("unexpected default value %v", *f.f.DefaultValues)
+	}
+
+	for _, value := range []string{"", "[ADMIN", "1 2", `"open`} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected an invalid default value", value)
+				}
+			}()
+			NewArg("first", "Int").Default(value)
+		}()
+	}
+}
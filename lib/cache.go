@@ -0,0 +1,181 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Version is the version of gqlmerge. The cached files are parsed again
+// when it changes.
+const Version = "v0.2.17"
+
+// cacheFormat is the version of the parsed files stored in the cache. It
+// must be bumped whenever the Schema or what the parser stores into it
+// changes, so that the stale files are parsed again.
+const cacheFormat = 1
+
+// Cache stores the parsed GraphQL files on disk, so that the unchanged
+// files are not parsed again by the next runs. A file is unchanged when
+// its size and modification time are the same, or else its content.
+type Cache struct {
+	Dir    string
+	Hits   int
+	Misses int
+
+	mu sync.Mutex
+}
+
+type cacheEntry struct {
+	Version string    `json:"version"`
+	Format  int       `json:"format"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash"`
+	Schema  *Schema   `json:"schema"`
+}
+
+// cache is used by the merge functions to parse the files when set.
+var cache *Cache
+
+// UseCache makes the merge functions parse the files through the cache, or
+// directly when c is nil.
+func UseCache(c *Cache) {
+	cache = c
+}
+
+// CurrentCache returns the cache set by UseCache, or nil.
+func CurrentCache() *Cache {
+	return cache
+}
+
+// NewCache returns a cache storing the files in dir, which is created if
+// needed.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+func (c *Cache) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("%d hits, %d misses", c.Hits, c.Misses)
+}
+
+// parse returns the parsed file from the cache, or parses it and stores it
+// into the cache.
+func (c *Cache) parse(path string) *Schema {
+	file, err := os.Open(path)
+	if err != nil {
+		errorf("%s", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		errorf("%s", err)
+	}
+
+	name := c.entryName(file.Name())
+	entry := c.read(name)
+	if entry != nil && entry.Path != file.Name() {
+		entry = nil
+	}
+	if entry != nil && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		c.count(true)
+		return entry.Schema
+	}
+
+	bs, err := io.ReadAll(file)
+	if err != nil {
+		errorf("%s", err)
+	}
+	sum := sha256.Sum256(bs)
+	hash := hex.EncodeToString(sum[:])
+
+	if entry != nil && entry.Hash == hash {
+		// touched but not changed
+		entry.ModTime = info.ModTime()
+		c.write(name, entry)
+		c.count(true)
+		return entry.Schema
+	}
+
+	sc := parseContent(bytes.NewReader(bs), file.Name())
+
+	c.write(name, &cacheEntry{
+		Version: Version,
+		Format:  cacheFormat,
+		Path:    file.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Hash:    hash,
+		Schema:  sc,
+	})
+	c.count(false)
+	return sc
+}
+
+func (c *Cache) count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.Hits++
+	} else {
+		c.Misses++
+	}
+}
+
+// entryName returns the name of the cache file of the GraphQL file.
+func (c *Cache) entryName(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// read returns the cached entry, or nil if there is none for the path,
+// the version and the format.
+func (c *Cache) read(name string) *cacheEntry {
+	bs, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(bs, entry); err != nil || entry.Version != Version || entry.Format != cacheFormat || entry.Schema == nil {
+		return nil
+	}
+	return entry
+}
+
+// write stores the entry. A failure only costs parsing the file again, so
+// it is ignored.
+func (c *Cache) write(name string, entry *cacheEntry) {
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(bs)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema", "user.graphql")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(src string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseCache(c)
+	defer UseCache(nil)
+
+	expect := func(hits, misses int, field string) {
+		s := parseSchema(nil, filepath.Dir(path))
+		if c.Hits != hits || c.Misses != misses {
+			t.Fatalf("expected %d hits, %d misses, got %s", hits, misses, c)
+		}
+		if len(s.Types) != 1 || s.Types[0].Fields[0].Name != field {
+			t.Fatalf("expected User.%s, got %+v", field, s.Types)
+		}
+		if !strings.HasSuffix(s.Types[0].Filename, "user.graphql") || s.Types[0].Line != 1 {
+			t.Fatalf("expected the position in user.graphql, got %+v", s.Types[0].BaseFileInfo)
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	write("type User { id: ID! }", now)
+	expect(0, 1, "id")
+	expect(1, 1, "id")
+
+	// touched but not changed
+	write("type User { id: ID! }", now.Add(time.Second))
+	expect(2, 1, "id")
+
+	write("type User { uuid: ID! }", now.Add(2*time.Second))
+	expect(2, 2, "uuid")
+
+	// the entries of another version are ignored
+	entry := c.entryName(path)
+	bs, err := os.ReadFile(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs = []byte(strings.Replace(string(bs), Version, "v0.0.0", 1))
+	if err := os.WriteFile(entry, bs, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect(2, 3, "uuid")
+
+	// and so are the entries of another format of the same version
+	if bs, err = os.ReadFile(entry); err != nil {
+		t.Fatal(err)
+	}
+	bs = []byte(strings.Replace(string(bs), fmt.Sprintf(`"format":%d`, cacheFormat), `"format":0`, 1))
+	if err := os.WriteFile(entry, bs, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect(2, 4, "uuid")
+}
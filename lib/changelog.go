@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// changelogSections are the sections of the changes of a type, in order.
+var changelogSections = []string{"Added", "Removed", "Changed", "Deprecated"}
+
+// ChangelogPaths merges the GraphQL files found in the old and the new
+// paths and writes the changes between them as Markdown.
+func ChangelogPaths(oldPaths, newPaths []string) (*string, error) {
+	cs, err := DiffPaths(oldPaths, newPaths)
+	if err != nil {
+		return nil, err
+	}
+	ss := Changelog(cs)
+	return &ss, nil
+}
+
+// Changelog writes the changes as Markdown to be pasted into the release
+// notes, e.g. under a version of CHANGELOG.md. The changes are grouped by
+// the type, or the directive, they belong to and then by whether something
+// was added, removed, changed or deprecated.
+func Changelog(cs Changes) string {
+	if len(cs) == 0 {
+		return "No changes in the schema.\n"
+	}
+
+	groups := []string{}
+	byGroup := map[string]map[string][]*Change{}
+	for _, c := range cs {
+		g := changelogGroup(c.Path)
+		if _, ok := byGroup[g]; !ok {
+			groups = append(groups, g)
+			byGroup[g] = map[string][]*Change{}
+		}
+		sec := changelogSection(c.Kind)
+		byGroup[g][sec] = append(byGroup[g][sec], c)
+	}
+
+	var b strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("### `%s`\n", g))
+		for _, sec := range changelogSections {
+			entries := byGroup[g][sec]
+			if len(entries) == 0 {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("\n#### %s\n\n", sec))
+			for _, c := range entries {
+				b.WriteString("- " + changelogEntry(c) + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// changelogGroup returns the type or the directive of the path, e.g. User
+// for User.posts(first:).
+func changelogGroup(path string) string {
+	if path == "schema" {
+		return path
+	}
+	if i := strings.IndexAny(path, ".("); i > 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func changelogSection(kind string) string {
+	switch kind {
+	case "type-added", "field-added", "input-field-added", "required-input-field-added",
+		"argument-added", "required-argument-added", "enum-value-added", "union-member-added",
+		"interface-added", "directive-added", "directive-location-added", "root-operation-added":
+		return "Added"
+	case "type-removed", "field-removed", "input-field-removed", "argument-removed",
+		"enum-value-removed", "union-member-removed", "interface-removed", "directive-removed",
+		"directive-location-removed", "root-operation-removed":
+		return "Removed"
+	case "deprecated":
+		return "Deprecated"
+	default:
+		return "Changed"
+	}
+}
+
+var quotedName = regexp.MustCompile(`"([^"\s]+)"`)
+
+// changelogEntry writes the message of the change with the names as code,
+// followed by the description of an added element.
+func changelogEntry(c *Change) string {
+	msg := quotedName.ReplaceAllString(c.Message, "`$1`")
+	msg = strings.ToUpper(msg[:1]) + msg[1:]
+	if c.Criticality == Breaking {
+		msg += " **(breaking)**"
+	} else if c.Criticality == Dangerous {
+		msg += " _(dangerous)_"
+	}
+	if d := descriptionText(c.Description); d != "" {
+		msg += "\n  > " + strings.Replace(d, "\n", "\n  > ", -1)
+	}
+	return msg
+}
+
+// descriptionText returns the text of a string or block string description.
+func descriptionText(description string) string {
+	d := strings.TrimSpace(description)
+	if strings.HasPrefix(d, `"""`) {
+		d = strings.TrimSuffix(strings.TrimPrefix(d, `"""`), `"""`)
+	} else {
+		d = strings.TrimSuffix(strings.TrimPrefix(d, `"`), `"`)
+	}
+
+	lines := []string{}
+	for _, l := range strings.Split(d, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
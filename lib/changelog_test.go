@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	var oldSrc = `
+	type Query {
+		user(id: ID!): User
+		legacy: String
+	}
+
+	type User {
+		id: ID!
+		email: String
+	}
+`
+	var newSrc = `
+	type Query {
+		user(id: ID!): User
+		legacy: String @deprecated(reason: "use user")
+	}
+
+	type User {
+		id: ID!
+		" the age in years "
+		age: Int
+	}
+`
+
+	parse := func(src string) *Schema {
+		s := Schema{}
+		p := NewParser(strings.NewReader(src), "schema.graphql")
+		s.Parse(p)
+		return mergeSchemas([]Schema{s}, ConflictError)
+	}
+
+	md := Changelog(Diff(parse(oldSrc), parse(newSrc)))
+
+	expected := "### `Query`\n" +
+		"\n#### Deprecated\n\n" +
+		"- Field `Query.legacy` was deprecated: use user\n" +
+		"\n### `User`\n" +
+		"\n#### Added\n\n" +
+		"- Field `User.age` was added\n" +
+		"  > the age in years\n" +
+		"\n#### Removed\n\n" +
+		"- Field `User.email` was removed **(breaking)**\n"
+
+	if md != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, md)
+	}
+}
@@ -0,0 +1,155 @@
+package lib
+
+import (
+	"strings"
+)
+
+// Contract describes a variant of the merged schema filtered by the
+// `@tag(name: "...")` directives.
+//
+// An element tagged with one of the Exclude tags is removed. When Include
+// is not empty, only the fields of object and interface types which are
+// tagged with one of the Include tags, or which belong to a type tagged
+// with one of them, are kept. Whatever is left dangling afterwards is
+// removed as well.
+type Contract struct {
+	Name    string
+	Include []string
+	Exclude []string
+}
+
+// MergeContracts merges the GraphQL files like Merge and additionally
+// generates a schema for each of the contracts, in the same order.
+func MergeContracts(indent string, contracts []Contract, paths ...string) (*string, []string) {
+	// the SDL is printed without error
+	ss, outputs, _ := MergeContractsWith(PrintOptions{Indent: indent}, contracts, paths...)
+	return ss, outputs
+}
+
+// MergeContractsWith is MergeContracts printing the schemas with the
+// options, e.g. in another format, which can fail.
+func MergeContractsWith(opts PrintOptions, contracts []Contract, paths ...string) (*string, []string, error) {
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, nil, nil
+	}
+
+	reportUndefinedTypes(schema)
+
+	outputs := make([]string, 0, len(contracts))
+	for _, c := range contracts {
+		ss, err := Print(schema.ApplyContract(c), opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, ss)
+	}
+
+	ss, err := Print(schema, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ss, outputs, nil
+}
+
+// ApplyContract returns a copy of the schema filtered by the contract.
+// The schema itself is left untouched.
+func (s *Schema) ApplyContract(c Contract) *Schema {
+	defined := s.definedTypeNames()
+	reachable := s.reachableTypeNames()
+	empty := s.emptyTypeNames()
+
+	fs := s.clone()
+	fs.filterTags(c)
+	fs.prune(defined, reachable, empty)
+	return fs
+}
+
+func (s *Schema) filterTags(c Contract) {
+	excluded := func(ds []*Directive) bool {
+		return hasTag(ds, c.Exclude)
+	}
+	included := func(ds []*Directive) bool {
+		return len(c.Include) == 0 || hasTag(ds, c.Include)
+	}
+
+	drop := VisitFuncs{Enter: func(cur *Cursor) Action {
+		if excluded(nodeDirectives(cur.Node())) {
+			cur.Delete()
+		}
+		return Continue
+	}}
+
+	Walk(s, Visitor{
+		KindType:      drop,
+		KindInterface: drop,
+		KindInput:     drop,
+		KindEnum:      drop,
+		KindEnumValue: drop,
+		KindUnion:     drop,
+		KindScalar:    drop,
+		KindArg:       drop,
+		KindField: {Enter: func(cur *Cursor) Action {
+			f := cur.Node().(*Field)
+			if excluded(f.Directives) {
+				cur.Delete()
+				return Continue
+			}
+			// the input fields are only subject to the exclude tags
+			_, input := cur.Parent().(*Input)
+			if !input && !included(nodeDirectives(cur.Parent())) && !included(f.Directives) {
+				cur.Delete()
+			}
+			return Continue
+		}},
+	})
+}
+
+// nodeDirectives returns the directives applied to the node.
+func nodeDirectives(n interface{}) []*Directive {
+	switch n := n.(type) {
+	case *Type:
+		return n.Directives
+	case *Interface:
+		return n.Directives
+	case *Input:
+		return n.Directives
+	case *Enum:
+		return n.Directives
+	case *EnumValue:
+		return n.Directives
+	case *Union:
+		return n.Directives
+	case *Scalar:
+		return n.Directives
+	case *Field:
+		return n.Directives
+	case *Arg:
+		return n.Directives
+	}
+	return nil
+}
+
+// hasTag reports whether one of the directives is `@tag` with one of the
+// given names.
+func hasTag(ds []*Directive, names []string) bool {
+	for _, d := range ds {
+		if d.Name != "tag" {
+			continue
+		}
+		for _, a := range d.DirectiveArgs {
+			if a.Name != "name" {
+				continue
+			}
+			for _, v := range a.Value {
+				v = strings.Trim(v, `"`)
+				for _, n := range names {
+					if v == n {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyContract(t *testing.T) {
+	var src = `
+	type Query {
+		me: User
+		users: [User!]! @tag(name: "internal")
+		audit(filter: AuditFilter): [AuditLog!]! @tag(name: "internal")
+		search(term: String, debug: Boolean @tag(name: "internal")): SearchResult
+		marker: Marker
+	}
+
+	type Marker {}
+
+	type User {
+		id: ID!
+		role: Role
+		secret: Secret @tag(name: "internal")
+	}
+
+	type Secret {
+		value: String
+	}
+
+	type AuditLog @tag(name: "internal") {
+		id: ID!
+	}
+
+	input AuditFilter {
+		after: String
+	}
+
+	union SearchResult = User | AuditLog
+
+	enum Role {
+		ADMIN
+		STAFF @tag(name: "internal")
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	public := merged.ApplyContract(Contract{Name: "public", Exclude: []string{"internal"}})
+	ms := MergedSchema{Indent: "  "}
+	out := ms.WriteSchema(public)
+
+	for _, removed := range []string{"users", "audit", "debug", "secret", "type Secret", "AuditLog", "AuditFilter", "STAFF"} {
+		if strings.Contains(out, removed) {
+			t.Fatalf("%q should be removed from the public contract:\n%s", removed, out)
+		}
+	}
+	for _, kept := range []string{"me: User", "search(term: String)", "union SearchResult = User", "ADMIN", "type Marker"} {
+		if !strings.Contains(out, kept) {
+			t.Fatalf("%q should be kept in the public contract:\n%s", kept, out)
+		}
+	}
+
+	internal := merged.ApplyContract(Contract{Name: "internal", Include: []string{"internal"}})
+	ms = MergedSchema{Indent: "  "}
+	out = ms.WriteSchema(internal)
+
+	if strings.Contains(out, "me: User") || strings.Contains(out, "search(") {
+		t.Fatalf("untagged root fields should be removed from the internal contract:\n%s", out)
+	}
+	for _, kept := range []string{"audit(filter: AuditFilter)", "type AuditLog", "input AuditFilter"} {
+		if !strings.Contains(out, kept) {
+			t.Fatalf("%q should be kept in the internal contract:\n%s", kept, out)
+		}
+	}
+
+	if len(merged.Types) != 5 || len(merged.Enums[0].EnumValues) != 2 {
+		t.Fatal("the merged schema should be left untouched")
+	}
+}
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found in the schema, e.g. by Validate.
+type Diagnostic struct {
+	BaseFileInfo
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Position(), d.Severity, d.Message, d.Rule)
+}
+
+// Position returns the position in the form of file:line:column, with the
+// file relative to the working directory when possible.
+func (b BaseFileInfo) Position() string {
+	name := b.Filename
+	if name != "" {
+		if rel, err := GetRelPath(name); err == nil {
+			name = *rel
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d", name, b.Line, b.Column)
+}
+
+type Diagnostics []*Diagnostic
+
+// HasErrors reports whether one of the diagnostics is an error.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// sort orders the diagnostics by their position.
+func (ds Diagnostics) sort() {
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i], ds[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
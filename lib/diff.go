@@ -0,0 +1,569 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Criticality tells how a change affects the clients of the schema.
+type Criticality int
+
+const (
+	// Breaking changes make existing operations invalid.
+	Breaking Criticality = iota
+	// Dangerous changes keep existing operations valid but may change
+	// their results, e.g. an enum value the clients do not handle.
+	Dangerous
+	// Safe changes do not affect existing operations.
+	Safe
+)
+
+func (c Criticality) String() string {
+	switch c {
+	case Breaking:
+		return "breaking"
+	case Dangerous:
+		return "dangerous"
+	case Safe:
+		return "safe"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a difference between two schemas.
+type Change struct {
+	Criticality Criticality
+	// Kind identifies the change, e.g. "field-removed".
+	Kind string
+	// Path is the schema coordinate of the changed element, e.g. "User",
+	// "User.email", "Query.users(first:)" or "@auth".
+	Path    string
+	Message string
+	// Description is the description of an added element.
+	Description string
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Criticality, c.Message)
+}
+
+type Changes []*Change
+
+// HasBreaking reports whether one of the changes is breaking.
+func (cs Changes) HasBreaking() bool {
+	for _, c := range cs {
+		if c.Criticality == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffPaths merges the GraphQL files found in the old and the new paths
+// and compares the merged schemas. The parse and merge errors are returned
+// instead of panicking.
+func DiffPaths(oldPaths, newPaths []string) (cs Changes, err error) {
+	defer recoverError(&err)
+
+	old := mergePaths(MergeOptions{}, oldPaths...)
+	if old == nil {
+		return nil, fmt.Errorf("not found any GraphQL files in %v", oldPaths)
+	}
+	new := mergePaths(MergeOptions{}, newPaths...)
+	if new == nil {
+		return nil, fmt.Errorf("not found any GraphQL files in %v", newPaths)
+	}
+	return Diff(old, new), nil
+}
+
+// Diff compares the old schema with the new one and classifies every
+// change as breaking, dangerous or safe for the clients of the old one.
+func Diff(old, new *Schema) Changes {
+	d := differ{old: old.index(), new: new.index()}
+	d.diffSchemaDefinition(old, new)
+	d.diffDirectiveDefinitions()
+	d.diffTypes()
+	return d.changes
+}
+
+type differ struct {
+	old, new *schemaIndex
+	changes  Changes
+}
+
+func (d *differ) add(c Criticality, kind, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		Criticality: c,
+		Kind:        kind,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// describe sets the description of the last change.
+func (d *differ) describe(descriptions *[]string) {
+	d.changes[len(d.changes)-1].Description = descriptionString(descriptions)
+}
+
+// rootOperation returns the type of the root operation, e.g. "query".
+func (s *Schema) rootOperation(op string) string {
+	if len(s.SchemaDefinitions) > 0 {
+		sd := s.SchemaDefinitions[0]
+		var name *string
+		switch op {
+		case "query":
+			name = sd.Query
+		case "mutation":
+			name = sd.Mutation
+		case "subscription":
+			name = sd.Subscription
+		}
+		if name != nil {
+			return *name
+		}
+		if sd.Query != nil || sd.Mutation != nil || sd.Subscription != nil {
+			return ""
+		}
+	}
+	name := strings.ToUpper(op[:1]) + op[1:]
+	for _, t := range s.Types {
+		if t.Name == name {
+			return name
+		}
+	}
+	return ""
+}
+
+func (d *differ) diffSchemaDefinition(old, new *Schema) {
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		o, n := old.rootOperation(op), new.rootOperation(op)
+		switch {
+		case o == n:
+		case o == "":
+			d.add(Safe, "root-operation-added", "schema", `%s root type "%s" was added`, op, n)
+		case n == "":
+			d.add(Breaking, "root-operation-removed", "schema", `%s root type "%s" was removed`, op, o)
+		default:
+			d.add(Breaking, "root-operation-changed", "schema", `%s root type changed from "%s" to "%s"`, op, o, n)
+		}
+	}
+}
+
+func (d *differ) diffDirectiveDefinitions() {
+	for _, name := range directiveNames(d.old, d.new) {
+		o, n := d.old.directives[name], d.new.directives[name]
+		path := "@" + name
+		switch {
+		case n == nil:
+			d.add(Breaking, "directive-removed", path, `directive "%s" was removed`, path)
+			continue
+		case o == nil:
+			d.add(Safe, "directive-added", path, `directive "%s" was added`, path)
+			d.describe(n.Descriptions)
+			continue
+		}
+
+		if o.Repeatable && !n.Repeatable {
+			d.add(Breaking, "directive-repeatable-removed", path, `directive "%s" is no longer repeatable`, path)
+		} else if !o.Repeatable && n.Repeatable {
+			d.add(Safe, "directive-repeatable-added", path, `directive "%s" became repeatable`, path)
+		}
+
+		for _, l := range o.Locations {
+			if !containsString(n.Locations, l) {
+				d.add(Breaking, "directive-location-removed", path, `location %s was removed from directive "%s"`, l, path)
+			}
+		}
+		for _, l := range n.Locations {
+			if !containsString(o.Locations, l) {
+				d.add(Safe, "directive-location-added", path, `location %s was added to directive "%s"`, l, path)
+			}
+		}
+
+		d.diffArgs(path, fmt.Sprintf(`directive "%s"`, path), o.Args, n.Args)
+	}
+}
+
+func (d *differ) diffTypes() {
+	names := map[string]bool{}
+	for _, x := range []*schemaIndex{d.old, d.new} {
+		for _, n := range x.typeNames() {
+			if !isBuiltInScalar(n) {
+				names[n] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		ok, nk := d.old.kind(name), d.new.kind(name)
+		switch {
+		case nk == kindUndefined:
+			d.add(Breaking, "type-removed", name, `%s "%s" was removed`, ok, name)
+			continue
+		case ok == kindUndefined:
+			d.add(Safe, "type-added", name, `%s "%s" was added`, nk, name)
+			descriptions, _ := d.new.typeInfo(name)
+			d.describe(descriptions)
+			continue
+		case ok != nk:
+			d.add(Breaking, "type-kind-changed", name, `"%s" changed from %s to %s`, name, ok, nk)
+			continue
+		}
+
+		desc := fmt.Sprintf(`%s "%s"`, ok, name)
+		od, odirs := d.old.typeInfo(name)
+		nd, ndirs := d.new.typeInfo(name)
+		d.diffDescription(name, desc, od, nd)
+		d.diffDirectives(name, desc, odirs, ndirs)
+
+		switch ok {
+		case kindObject:
+			d.diffImplements(name, d.old.types[name].ImplTypes, d.new.types[name].ImplTypes)
+			d.diffFields(name, false, d.old.types[name].Fields, d.new.types[name].Fields)
+		case kindInterface:
+			d.diffFields(name, false, d.old.interfaces[name].Fields, d.new.interfaces[name].Fields)
+		case kindUnion:
+			d.diffUnionMembers(name, d.old.unions[name].Types, d.new.unions[name].Types)
+		case kindEnum:
+			d.diffEnumValues(name, d.old.enums[name].EnumValues, d.new.enums[name].EnumValues)
+		case kindInput:
+			d.diffFields(name, true, d.old.inputs[name].Fields, d.new.inputs[name].Fields)
+		}
+	}
+}
+
+// typeInfo returns the descriptions and the directives of the named type.
+func (x *schemaIndex) typeInfo(name string) (*[]string, []*Directive) {
+	switch x.kind(name) {
+	case kindObject:
+		return x.types[name].Descriptions, x.types[name].Directives
+	case kindInterface:
+		return x.interfaces[name].Descriptions, x.interfaces[name].Directives
+	case kindUnion:
+		return x.unions[name].Descriptions, x.unions[name].Directives
+	case kindEnum:
+		return x.enums[name].Descriptions, x.enums[name].Directives
+	case kindInput:
+		return x.inputs[name].Descriptions, x.inputs[name].Directives
+	}
+	if c, ok := x.scalars[name]; ok {
+		return c.Descriptions, c.Directives
+	}
+	return nil, nil
+}
+
+func (d *differ) diffImplements(name string, old, new []string) {
+	for _, i := range old {
+		if !containsString(new, i) {
+			d.add(Breaking, "interface-removed", name, `"%s" no longer implements "%s"`, name, i)
+		}
+	}
+	for _, i := range new {
+		if !containsString(old, i) {
+			d.add(Dangerous, "interface-added", name, `"%s" implements "%s"`, name, i)
+		}
+	}
+}
+
+func (d *differ) diffUnionMembers(name string, old, new []string) {
+	for _, m := range old {
+		if !containsString(new, m) {
+			d.add(Breaking, "union-member-removed", name, `"%s" was removed from union "%s"`, m, name)
+		}
+	}
+	for _, m := range new {
+		if !containsString(old, m) {
+			d.add(Dangerous, "union-member-added", name, `"%s" was added to union "%s"`, m, name)
+		}
+	}
+}
+
+func (d *differ) diffEnumValues(name string, old, new []EnumValue) {
+	values := map[string]*EnumValue{}
+	for i := range new {
+		values[new[i].Name] = &new[i]
+	}
+	for i := range old {
+		o := &old[i]
+		path := name + "." + o.Name
+		n, ok := values[o.Name]
+		if !ok {
+			d.add(Breaking, "enum-value-removed", path, `enum value "%s" was removed`, path)
+			continue
+		}
+		d.diffDescription(path, fmt.Sprintf(`enum value "%s"`, path), o.Descriptions, n.Descriptions)
+		d.diffDirectives(path, fmt.Sprintf(`enum value "%s"`, path), o.Directives, n.Directives)
+	}
+
+	values = map[string]*EnumValue{}
+	for i := range old {
+		values[old[i].Name] = &old[i]
+	}
+	for _, n := range new {
+		if _, ok := values[n.Name]; !ok {
+			path := name + "." + n.Name
+			d.add(Dangerous, "enum-value-added", path, `enum value "%s" was added`, path)
+			d.describe(n.Descriptions)
+		}
+	}
+}
+
+// diffFields compares the fields of object and interface types or, when
+// input is true, of input object types.
+func (d *differ) diffFields(name string, input bool, old, new []*Field) {
+	what := "field"
+	if input {
+		what = "input field"
+	}
+
+	fields := map[string]*Field{}
+	for _, f := range new {
+		fields[f.Name] = f
+	}
+	for _, o := range old {
+		path := name + "." + o.Name
+		desc := fmt.Sprintf(`%s "%s"`, what, path)
+		n, ok := fields[o.Name]
+		if !ok {
+			d.add(Breaking, strings.Replace(what, " ", "-", -1)+"-removed", path, `%s was removed`, desc)
+			continue
+		}
+
+		if input {
+			d.diffInputType(path, desc, fieldTypeRef(o), fieldTypeRef(n))
+			d.diffDefaultValue(path, desc, o.DefaultValues, n.DefaultValues)
+		} else {
+			d.diffOutputType(path, desc, fieldTypeRef(o), fieldTypeRef(n))
+			d.diffArgs(path, desc, o.Args, n.Args)
+		}
+		d.diffDescription(path, desc, o.Descriptions, n.Descriptions)
+		d.diffDirectives(path, desc, o.Directives, n.Directives)
+	}
+
+	fields = map[string]*Field{}
+	for _, f := range old {
+		fields[f.Name] = f
+	}
+	for _, n := range new {
+		if _, ok := fields[n.Name]; ok {
+			continue
+		}
+		path := name + "." + n.Name
+		ref := fieldTypeRef(n)
+		if input && ref.nonNull() && n.DefaultValues == nil {
+			d.add(Breaking, "required-input-field-added", path, `required input field "%s" of type %s was added`, path, ref)
+		} else if input {
+			d.add(Safe, "input-field-added", path, `input field "%s" was added`, path)
+		} else {
+			d.add(Safe, "field-added", path, `field "%s" was added`, path)
+		}
+		d.describe(n.Descriptions)
+	}
+}
+
+// diffArgs compares the arguments of a field or a directive definition.
+func (d *differ) diffArgs(parent, parentDesc string, old, new []*Arg) {
+	args := map[string]*Arg{}
+	for _, a := range new {
+		args[a.Name] = a
+	}
+	for _, o := range old {
+		path := fmt.Sprintf("%s(%s:)", parent, o.Name)
+		desc := fmt.Sprintf(`argument "%s" of %s`, o.Name, parentDesc)
+		n, ok := args[o.Name]
+		if !ok {
+			d.add(Breaking, "argument-removed", path, `%s was removed`, desc)
+			continue
+		}
+		d.diffInputType(path, desc, argTypeRef(o), argTypeRef(n))
+		d.diffDefaultValue(path, desc, o.DefaultValues, n.DefaultValues)
+		d.diffDescription(path, desc, o.Descriptions, n.Descriptions)
+		d.diffDirectives(path, desc, o.Directives, n.Directives)
+	}
+
+	args = map[string]*Arg{}
+	for _, a := range old {
+		args[a.Name] = a
+	}
+	for _, n := range new {
+		if _, ok := args[n.Name]; ok {
+			continue
+		}
+		path := fmt.Sprintf("%s(%s:)", parent, n.Name)
+		if isRequiredArg(n) {
+			d.add(Breaking, "required-argument-added", path, `required argument "%s" of type %s was added to %s`, n.Name, argTypeRef(n), parentDesc)
+		} else {
+			d.add(Safe, "argument-added", path, `argument "%s" was added to %s`, n.Name, parentDesc)
+		}
+		d.describe(n.Descriptions)
+	}
+}
+
+// diffOutputType allows the new type to be stricter than the old one, e.g.
+// String to String!, as the clients still receive what they expect.
+func (d *differ) diffOutputType(path, desc string, old, new typeRef) {
+	if old == new {
+		return
+	}
+	if d.new.isSubType(new, old) {
+		d.add(Safe, "field-type-changed", path, `type of %s changed from %s to %s`, desc, old, new)
+		return
+	}
+	d.add(Breaking, "field-type-changed", path, `type of %s changed from %s to %s`, desc, old, new)
+}
+
+// diffInputType allows the new type to be looser than the old one, e.g.
+// String! to String, as the values the clients send are still accepted.
+func (d *differ) diffInputType(path, desc string, old, new typeRef) {
+	if old == new {
+		return
+	}
+	if old.Name == new.Name && d.new.isSubType(old, new) {
+		d.add(Safe, "input-type-changed", path, `type of %s changed from %s to %s`, desc, old, new)
+		return
+	}
+	d.add(Breaking, "input-type-changed", path, `type of %s changed from %s to %s`, desc, old, new)
+}
+
+func (d *differ) diffDefaultValue(path, desc string, old, new *[]string) {
+	o, n := defaultValueString(old), defaultValueString(new)
+	if o == n {
+		return
+	}
+	switch {
+	case o == "":
+		d.add(Safe, "default-value-added", path, `default value %s was added to %s`, n, desc)
+	case n == "":
+		d.add(Dangerous, "default-value-removed", path, `default value %s was removed from %s`, o, desc)
+	default:
+		d.add(Dangerous, "default-value-changed", path, `default value of %s changed from %s to %s`, desc, o, n)
+	}
+}
+
+func defaultValueString(values *[]string) string {
+	if values == nil {
+		return ""
+	}
+	return strings.Join(*values, ", ")
+}
+
+func (d *differ) diffDescription(path, desc string, old, new *[]string) {
+	o, n := descriptionString(old), descriptionString(new)
+	if o == n {
+		return
+	}
+	switch {
+	case o == "":
+		d.add(Safe, "description-added", path, `description was added to %s`, desc)
+	case n == "":
+		d.add(Safe, "description-removed", path, `description was removed from %s`, desc)
+	default:
+		d.add(Safe, "description-changed", path, `description of %s changed`, desc)
+	}
+}
+
+// descriptionString returns the description as it is written in the
+// merged schema, leaving the comments out.
+func descriptionString(descriptions *[]string) string {
+	if descriptions == nil || len(*descriptions) == 0 {
+		return ""
+	}
+	if d := (*descriptions)[0]; strings.HasPrefix(d, `"`) {
+		return d
+	}
+	return ""
+}
+
+// diffDirectives compares the directives applied to an element. The
+// deprecations are reported on their own.
+func (d *differ) diffDirectives(path, desc string, old, new []*Directive) {
+	start := len(d.changes)
+	olds, news := directiveStrings(old), directiveStrings(new)
+	for name, o := range olds {
+		n, ok := news[name]
+		switch {
+		case name == "deprecated" && !ok:
+			d.add(Safe, "deprecation-removed", path, `%s is no longer deprecated`, desc)
+		case !ok:
+			d.add(Dangerous, "directive-usage-removed", path, `directive "@%s" was removed from %s`, name, desc)
+		case o != n:
+			d.add(Dangerous, "directive-usage-changed", path, `directive "@%s" of %s changed from %s to %s`, name, desc, o, n)
+		}
+	}
+	for name, n := range news {
+		if _, ok := olds[name]; ok {
+			continue
+		}
+		if name == "deprecated" {
+			d.add(Safe, "deprecated", path, `%s was deprecated%s`, desc, deprecationReason(new))
+		} else {
+			d.add(Dangerous, "directive-usage-added", path, `directive %s was added to %s`, n, desc)
+		}
+	}
+
+	// the maps are iterated in a random order
+	added := d.changes[start:]
+	sort.SliceStable(added, func(i, j int) bool {
+		return added[i].Message < added[j].Message
+	})
+}
+
+// directiveStrings returns the applied directives by name, written as
+// in the schema, e.g. @auth(requires: ADMIN).
+func directiveStrings(ds []*Directive) map[string]string {
+	m := map[string]string{}
+	for _, dir := range ds {
+		var sb strings.Builder
+		ms := MergedSchema{buf: schemaWriter{w: &sb}}
+		ms.stitchDirectives([]*Directive{dir})
+		m[dir.Name] += strings.TrimSpace(sb.String())
+	}
+	return m
+}
+
+func deprecationReason(ds []*Directive) string {
+	for _, dir := range ds {
+		if dir.Name != "deprecated" {
+			continue
+		}
+		for _, a := range dir.DirectiveArgs {
+			if a.Name == "reason" && len(a.Value) > 0 {
+				return ": " + strings.Trim(a.Value[0], `"`)
+			}
+		}
+	}
+	return ""
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveNames returns the names of the directives defined in either
+// index, sorted.
+func directiveNames(indexes ...*schemaIndex) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, x := range indexes {
+		for k := range x.directives {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
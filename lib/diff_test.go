@@ -0,0 +1,136 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var oldSrc = `
+	directive @auth(role: String) on FIELD_DEFINITION | OBJECT
+
+	type Query {
+		user(id: ID!): User
+		users(first: Int = 10): [User!]!
+		legacy: String
+	}
+
+	type User implements Node {
+		id: ID!
+		name: String
+		email: String!
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	union Result = User | Post
+
+	type Post {
+		id: ID!
+	}
+
+	enum Role {
+		ADMIN
+		GUEST
+	}
+
+	input UserInput {
+		name: String!
+		role: Role
+	}
+`
+	var newSrc = `
+	directive @auth(role: String, scope: String!) on FIELD_DEFINITION
+
+	type Query {
+		user(id: ID!, active: Boolean): User
+		users(first: Int = 20, after: String!): [User!]!
+		legacy: String @deprecated(reason: "use user")
+	}
+
+	type User {
+		id: ID!
+		name: String!
+		email: String
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	union Result = Post
+
+	type Post {
+		id: ID!
+		title: String
+	}
+
+	enum Role {
+		ADMIN
+		EDITOR
+	}
+
+	input UserInput {
+		name: String
+		role: Role
+		age: Int!
+	}
+`
+
+	parse := func(src string) *Schema {
+		s := Schema{}
+		p := NewParser(strings.NewReader(src), "schema.graphql")
+		s.Parse(p)
+		return mergeSchemas([]Schema{s}, ConflictError)
+	}
+
+	cs := Diff(parse(oldSrc), parse(newSrc))
+	if !cs.HasBreaking() {
+		t.Fatal("should have breaking changes")
+	}
+
+	expected := []string{
+		`breaking: location OBJECT was removed from directive "@auth"`,
+		`breaking: required argument "scope" of type String! was added to directive "@auth"`,
+		`safe: argument "active" was added to field "Query.user"`,
+		`dangerous: default value of argument "first" of field "Query.users" changed from 10 to 20`,
+		`breaking: required argument "after" of type String! was added to field "Query.users"`,
+		`safe: field "Query.legacy" was deprecated: use user`,
+		`breaking: "User" no longer implements "Node"`,
+		`safe: type of field "User.name" changed from String to String!`,
+		`breaking: type of field "User.email" changed from String! to String`,
+		`breaking: "User" was removed from union "Result"`,
+		`safe: field "Post.title" was added`,
+		`breaking: enum value "Role.GUEST" was removed`,
+		`dangerous: enum value "Role.EDITOR" was added`,
+		`safe: type of input field "UserInput.name" changed from String! to String`,
+		`breaking: required input field "UserInput.age" of type Int! was added`,
+	}
+
+	messages := []string{}
+	for _, c := range cs {
+		messages = append(messages, c.String())
+	}
+	all := strings.Join(messages, "\n")
+
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Fatalf("expected %q in\n%s", e, all)
+		}
+	}
+	if len(cs) != len(expected) {
+		t.Fatalf("expected %d changes, got\n%s", len(expected), all)
+	}
+
+	if cs := Diff(parse(newSrc), parse(newSrc)); len(cs) != 0 {
+		t.Fatalf("expected no changes, got %v", cs)
+	}
+}
+
+func TestDiffPathsConflict(t *testing.T) {
+	if _, err := DiffPaths([]string{conflictingDir(t)}, []string{conflictingDir(t)}); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+}
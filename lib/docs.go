@@ -0,0 +1,534 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DocsFormat is the format of the documentation generated by Docs.
+type DocsFormat string
+
+const (
+	// DocsMarkdown is a directory of Markdown pages, e.g. to be browsed on
+	// GitHub.
+	DocsMarkdown DocsFormat = "markdown"
+	// DocsHTML is a static site of HTML pages which need no other file.
+	DocsHTML DocsFormat = "html"
+)
+
+// Docs returns the documentation pages of the schema by slash separated
+// path: an index and, in the types directory so that no type overwrites
+// the index, a page per type with its description, fields, arguments, values,
+// deprecations, implemented interfaces, possible types and the fields and
+// types which use it.
+func Docs(s *Schema, format DocsFormat) (map[string]string, error) {
+	site := newDocSite(s)
+	switch format {
+	case DocsMarkdown:
+		return site.markdown(), nil
+	case DocsHTML:
+		return site.html()
+	default:
+		return nil, fmt.Errorf(`unknown docs format "%s", it should be "markdown" or "html"`, format)
+	}
+}
+
+// WriteDocs writes the documentation pages of the schema into the
+// directory, which is created if needed. The other files of the directory
+// are left untouched.
+func WriteDocs(dir string, s *Schema, format DocsFormat) (int, error) {
+	pages, err := Docs(s, format)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, docsTypesDir), 0755); err != nil {
+		return 0, err
+	}
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(pages), nil
+}
+
+// docsTypesDir is the directory of the type pages, next to the index.
+const docsTypesDir = "types"
+
+// docSite is the content of the documentation, shared by the formats.
+type docSite struct {
+	Description string
+	Roots       []docRoot
+	Sections    []docSection
+	Directives  []*docDirective
+	pages       map[string]*docPage
+}
+
+type docRoot struct {
+	Operation string
+	Type      string
+}
+
+// docSection lists the pages of a kind of type on the index.
+type docSection struct {
+	Title string
+	Pages []*docPage
+}
+
+type docPage struct {
+	Name        string
+	Kind        string
+	Description string
+	SpecifiedBy string
+	Interfaces  []string
+	Fields      []*docField
+	InputFields []*docField
+	Values      []*docValue
+	// PossibleTypes are the members of a union or the object types
+	// implementing an interface.
+	PossibleTypes []string
+	UsedBy        []docRef
+}
+
+// docField is a field, an argument or an input field.
+type docField struct {
+	Name        string
+	Type        string
+	TypeName    string
+	Default     string
+	Description string
+	Deprecated  *string
+	Args        []*docField
+}
+
+type docValue struct {
+	Name        string
+	Description string
+	Deprecated  *string
+}
+
+type docDirective struct {
+	Name        string
+	Description string
+	Repeatable  bool
+	Locations   []string
+	Args        []*docField
+}
+
+// docRef is a use of a type, by a field or an argument of the type Page, or
+// by the type itself, e.g. a union.
+type docRef struct {
+	Page  string
+	Field string
+	Arg   string
+}
+
+func (r docRef) String() string {
+	s := r.Page
+	if r.Field != "" {
+		s += "." + r.Field
+	}
+	if r.Arg != "" {
+		s += "(" + r.Arg + ":)"
+	}
+	return s
+}
+
+func newDocSite(s *Schema) *docSite {
+	site := &docSite{pages: map[string]*docPage{}}
+	if len(s.SchemaDefinitions) > 0 {
+		site.Description = docDescription(s.SchemaDefinitions[0].Descriptions)
+	}
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		if name := s.rootOperation(op); name != "" {
+			site.Roots = append(site.Roots, docRoot{Operation: op, Type: name})
+		}
+	}
+
+	add := func(title string, pages []*docPage) {
+		sort.Slice(pages, func(i, j int) bool { return pages[i].Name < pages[j].Name })
+		for _, p := range pages {
+			site.pages[p.Name] = p
+		}
+		if len(pages) > 0 {
+			site.Sections = append(site.Sections, docSection{Title: title, Pages: pages})
+		}
+	}
+
+	objects := []*docPage{}
+	for _, t := range s.Types {
+		objects = append(objects, &docPage{
+			Name:        t.Name,
+			Kind:        kindObject.String(),
+			Description: docDescription(t.Descriptions),
+			Interfaces:  append([]string{}, t.ImplTypes...),
+			Fields:      docFields(t.Fields),
+		})
+	}
+	add("Objects", objects)
+
+	interfaces := []*docPage{}
+	for _, i := range s.Interfaces {
+		p := &docPage{
+			Name:        i.Name,
+			Kind:        kindInterface.String(),
+			Description: docDescription(i.Descriptions),
+			Fields:      docFields(i.Fields),
+		}
+		for _, t := range s.Types {
+			if containsString(t.ImplTypes, i.Name) {
+				p.PossibleTypes = append(p.PossibleTypes, t.Name)
+			}
+		}
+		sort.Strings(p.PossibleTypes)
+		interfaces = append(interfaces, p)
+	}
+	add("Interfaces", interfaces)
+
+	unions := []*docPage{}
+	for _, u := range s.Unions {
+		unions = append(unions, &docPage{
+			Name:          u.Name,
+			Kind:          kindUnion.String(),
+			Description:   docDescription(u.Descriptions),
+			PossibleTypes: append([]string{}, u.Types...),
+		})
+	}
+	add("Unions", unions)
+
+	enums := []*docPage{}
+	for _, e := range s.Enums {
+		p := &docPage{
+			Name:        e.Name,
+			Kind:        kindEnum.String(),
+			Description: docDescription(e.Descriptions),
+		}
+		for _, v := range e.EnumValues {
+			p.Values = append(p.Values, &docValue{
+				Name:        v.Name,
+				Description: docDescription(v.Descriptions),
+				Deprecated:  deprecation(v.Directives),
+			})
+		}
+		enums = append(enums, p)
+	}
+	add("Enums", enums)
+
+	inputs := []*docPage{}
+	for _, i := range s.Inputs {
+		inputs = append(inputs, &docPage{
+			Name:        i.Name,
+			Kind:        kindInput.String(),
+			Description: docDescription(i.Descriptions),
+			InputFields: docFields(i.Fields),
+		})
+	}
+	add("Input objects", inputs)
+
+	scalars := []*docPage{}
+	for _, sc := range s.Scalars {
+		p := &docPage{
+			Name:        sc.Name,
+			Kind:        kindScalar.String(),
+			Description: docDescription(sc.Descriptions),
+		}
+		if url := directiveArgString(sc.Directives, "specifiedBy", "url"); url != nil {
+			p.SpecifiedBy = *url
+		}
+		scalars = append(scalars, p)
+	}
+	add("Scalars", scalars)
+
+	for _, d := range s.DirectiveDefinitions {
+		site.Directives = append(site.Directives, &docDirective{
+			Name:        d.Name,
+			Description: docDescription(d.Descriptions),
+			Repeatable:  d.Repeatable,
+			Locations:   d.Locations,
+			Args:        docArgs(d.Args),
+		})
+	}
+	sort.Slice(site.Directives, func(i, j int) bool { return site.Directives[i].Name < site.Directives[j].Name })
+
+	site.collectUses()
+	return site
+}
+
+// collectUses sets the pages which use each page, sorted.
+func (site *docSite) collectUses() {
+	use := func(name string, r docRef) {
+		if p, ok := site.pages[name]; ok {
+			p.UsedBy = append(p.UsedBy, r)
+		}
+	}
+	fields := func(page string, fs []*docField) {
+		for _, f := range fs {
+			use(f.TypeName, docRef{Page: page, Field: f.Name})
+			for _, a := range f.Args {
+				use(a.TypeName, docRef{Page: page, Field: f.Name, Arg: a.Name})
+			}
+		}
+	}
+	for _, sec := range site.Sections {
+		for _, p := range sec.Pages {
+			fields(p.Name, p.Fields)
+			fields(p.Name, p.InputFields)
+			for _, i := range p.Interfaces {
+				use(i, docRef{Page: p.Name})
+			}
+			if p.Kind == kindUnion.String() {
+				for _, t := range p.PossibleTypes {
+					use(t, docRef{Page: p.Name})
+				}
+			}
+		}
+	}
+
+	for _, p := range site.pages {
+		sort.Slice(p.UsedBy, func(i, j int) bool { return p.UsedBy[i].String() < p.UsedBy[j].String() })
+	}
+}
+
+// hasPage reports whether the type has a page, as opposed to the built-in
+// and undefined types.
+func (site *docSite) hasPage(name string) bool {
+	_, ok := site.pages[name]
+	return ok
+}
+
+func docFields(fs []*Field) []*docField {
+	dfs := make([]*docField, 0, len(fs))
+	for _, f := range fs {
+		ref := fieldTypeRef(f)
+		dfs = append(dfs, &docField{
+			Name:        f.Name,
+			Type:        ref.String(),
+			TypeName:    f.Type,
+			Default:     docDefault(f.DefaultValues),
+			Description: docDescription(f.Descriptions),
+			Deprecated:  deprecation(f.Directives),
+			Args:        docArgs(f.Args),
+		})
+	}
+	return dfs
+}
+
+func docArgs(as []*Arg) []*docField {
+	das := make([]*docField, 0, len(as))
+	for _, a := range as {
+		ref := argTypeRef(a)
+		das = append(das, &docField{
+			Name:        a.Name,
+			Type:        ref.String(),
+			TypeName:    a.Type,
+			Default:     docDefault(a.DefaultValues),
+			Description: docDescription(a.Descriptions),
+			Deprecated:  deprecation(a.Directives),
+		})
+	}
+	return das
+}
+
+// docDefault returns the default value written as in the schema, or an
+// empty string.
+func docDefault(values *[]string) string {
+	if values == nil {
+		return ""
+	}
+	if v := parseDefaultValue(*values); v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+func docDescription(descriptions *[]string) string {
+	if d := introspectionDescription(descriptions); d != nil {
+		return strings.TrimSpace(*d)
+	}
+	return ""
+}
+
+// summary returns the first line of the description.
+func summary(d string) string {
+	line, _, _ := strings.Cut(d, "\n")
+	return line
+}
+
+// markdown returns the Markdown pages: README.md as the index and a page
+// per type named after it in the types directory.
+func (site *docSite) markdown() map[string]string {
+	pages := map[string]string{"README.md": site.markdownIndex()}
+	for name, p := range site.pages {
+		pages[docsTypesDir+"/"+name+".md"] = site.markdownPage(p)
+	}
+	return pages
+}
+
+func (site *docSite) markdownIndex() string {
+	var b strings.Builder
+	b.WriteString("# Schema\n")
+	if site.Description != "" {
+		b.WriteString("\n" + site.Description + "\n")
+	}
+
+	if len(site.Roots) > 0 {
+		b.WriteString("\n## Operations\n\n")
+		for _, r := range site.Roots {
+			fmt.Fprintf(&b, "- %s: %s\n", r.Operation, site.markdownTypeLink(docsTypesDir+"/", r.Type, r.Type))
+		}
+	}
+
+	for _, sec := range site.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", sec.Title)
+		for _, p := range sec.Pages {
+			b.WriteString("- " + site.markdownTypeLink(docsTypesDir+"/", p.Name, p.Name))
+			if s := summary(p.Description); s != "" {
+				b.WriteString(": " + s)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(site.Directives) > 0 {
+		b.WriteString("\n## Directives\n")
+		for _, d := range site.Directives {
+			fmt.Fprintf(&b, "\n### `@%s`\n\n", d.Name)
+			if d.Description != "" {
+				b.WriteString(d.Description + "\n\n")
+			}
+			repeatable := ""
+			if d.Repeatable {
+				repeatable = "repeatable, "
+			}
+			fmt.Fprintf(&b, "_%son %s_\n", repeatable, strings.Join(d.Locations, " | "))
+			site.markdownArgs(&b, docsTypesDir+"/", d.Args)
+		}
+	}
+
+	return b.String()
+}
+
+func (site *docSite) markdownPage(p *docPage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n_%s_", p.Name, p.Kind)
+	if len(p.Interfaces) > 0 {
+		b.WriteString(" implementing " + site.markdownTypeLinks(p.Interfaces))
+	}
+	b.WriteString("\n\n[Schema](../README.md)\n")
+	if p.Description != "" {
+		b.WriteString("\n" + p.Description + "\n")
+	}
+	if p.SpecifiedBy != "" {
+		fmt.Fprintf(&b, "\nSpecified by <%s>\n", p.SpecifiedBy)
+	}
+
+	if len(p.Fields) > 0 {
+		b.WriteString("\n## Fields\n")
+		for _, f := range p.Fields {
+			fmt.Fprintf(&b, "\n### `%s`\n\n%s\n", f.Name, site.markdownTypeLink("", f.TypeName, "`"+f.Type+"`"))
+			if f.Deprecated != nil {
+				fmt.Fprintf(&b, "\n> **Deprecated:** %s\n", *f.Deprecated)
+			}
+			if f.Description != "" {
+				b.WriteString("\n" + f.Description + "\n")
+			}
+			site.markdownArgs(&b, "", f.Args)
+		}
+	}
+
+	if len(p.InputFields) > 0 {
+		b.WriteString("\n## Fields\n\n| Field | Type | Default | Description |\n| --- | --- | --- | --- |\n")
+		for _, f := range p.InputFields {
+			site.markdownRow(&b, "", f)
+		}
+	}
+
+	if len(p.Values) > 0 {
+		b.WriteString("\n## Values\n\n| Value | Description |\n| --- | --- |\n")
+		for _, v := range p.Values {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", v.Name, markdownCell(v.Description, v.Deprecated))
+		}
+	}
+
+	if len(p.PossibleTypes) > 0 {
+		title := "Possible types"
+		if p.Kind == kindInterface.String() {
+			title = "Implemented by"
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, t := range p.PossibleTypes {
+			b.WriteString("- " + site.markdownTypeLink("", t, t) + "\n")
+		}
+	}
+
+	if len(p.UsedBy) > 0 {
+		b.WriteString("\n## Used by\n\n")
+		for _, r := range p.UsedBy {
+			link := r.Page + ".md"
+			if r.Field != "" && len(site.pages[r.Page].Fields) > 0 {
+				link += "#" + strings.ToLower(r.Field)
+			}
+			fmt.Fprintf(&b, "- [`%s`](%s)\n", r, link)
+		}
+	}
+
+	return b.String()
+}
+
+// markdownArgs writes the table of the arguments, linking their types from
+// the directory of the types relative to the page.
+func (site *docSite) markdownArgs(b *strings.Builder, dir string, args []*docField) {
+	if len(args) == 0 {
+		return
+	}
+	b.WriteString("\n| Argument | Type | Default | Description |\n| --- | --- | --- | --- |\n")
+	for _, a := range args {
+		site.markdownRow(b, dir, a)
+	}
+}
+
+func (site *docSite) markdownRow(b *strings.Builder, dir string, f *docField) {
+	def := ""
+	if f.Default != "" {
+		def = "`" + strings.ReplaceAll(f.Default, "|", `\|`) + "`"
+	}
+	fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", f.Name, site.markdownTypeLink(dir, f.TypeName, "`"+f.Type+"`"), def, markdownCell(f.Description, f.Deprecated))
+}
+
+// markdownTypeLink links the text to the page of the type, if any, in the
+// directory of the types relative to the page, "" from a type page.
+func (site *docSite) markdownTypeLink(dir, name, text string) string {
+	if !site.hasPage(name) {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s%s.md)", text, dir, name)
+}
+
+func (site *docSite) markdownTypeLinks(names []string) string {
+	links := make([]string, 0, len(names))
+	for _, n := range names {
+		links = append(links, site.markdownTypeLink("", n, n))
+	}
+	return strings.Join(links, ", ")
+}
+
+// markdownCell returns the description and the deprecation on a single
+// line of a table.
+func markdownCell(description string, deprecated *string) string {
+	cell := description
+	if deprecated != nil {
+		dep := "**Deprecated:** " + *deprecated
+		if cell != "" {
+			dep += "<br>"
+		}
+		cell = dep + cell
+	}
+	cell = strings.ReplaceAll(cell, "|", `\|`)
+	return strings.ReplaceAll(cell, "\n", "<br>")
+}
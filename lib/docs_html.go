@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"html/template"
+	"strings"
+)
+
+// docsHTMLTemplate renders the index and the type pages. The style is
+// inlined so that the pages need no other file. The links are relative to
+// the index, the type pages setting its directory as their base.
+const docsHTMLTemplate = `{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if .Base}}<base href="{{.Base}}">
+{{end}}<title>{{.Title}}</title>
+<style>
+body { margin: 0; display: flex; font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; }
+nav { flex: 0 0 16em; height: 100vh; position: sticky; top: 0; overflow-y: auto; padding: 1em; background: #f6f8fa; border-right: 1px solid #d0d7de; box-sizing: border-box; }
+nav h2 { font-size: 0.8em; text-transform: uppercase; color: #57606a; margin: 1.2em 0 0.3em; }
+nav ul { list-style: none; margin: 0; padding: 0; }
+main { flex: 1; max-width: 60em; padding: 1em 2em; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+code { font: 0.9em SFMono-Regular, Consolas, Menlo, monospace; background: #f6f8fa; padding: 0.1em 0.3em; border-radius: 4px; }
+.kind { color: #57606a; font-style: italic; }
+.description { white-space: pre-wrap; }
+.deprecated { background: #fff8c5; border-left: 4px solid #d4a72c; padding: 0.3em 0.8em; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #d0d7de; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+h3 { margin-bottom: 0.2em; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "nav"}}<nav>
+<a href="index.html"><strong>Schema</strong></a>
+{{range .Sections}}<h2>{{.Title}}</h2>
+<ul>
+{{range .Pages}}<li><a href="{{typesDir}}/{{.Name}}.html">{{.Name}}</a></li>
+{{end}}</ul>
+{{end}}</nav>
+{{end}}
+
+{{define "description"}}{{if .}}<p class="description">{{.}}</p>
+{{end}}{{end}}
+
+{{define "deprecated"}}{{if .}}<p class="deprecated"><strong>Deprecated:</strong> {{.}}</p>
+{{end}}{{end}}
+
+{{define "args"}}{{if .}}<table>
+<tr><th>Name</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{range .}}<tr id="{{.Name}}"><td><code>{{.Name}}</code></td><td>{{typeLink .TypeName .Type}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{template "deprecated" .Deprecated}}{{template "description" .Description}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+
+{{define "index"}}{{template "head" (head "Schema" "")}}{{template "nav" .}}<main>
+<h1>Schema</h1>
+{{template "description" .Description}}
+{{if .Roots}}<h2>Operations</h2>
+<ul>
+{{range .Roots}}<li>{{.Operation}}: {{typeLink .Type .Type}}</li>
+{{end}}</ul>
+{{end}}
+{{range .Sections}}<h2>{{.Title}}</h2>
+<ul>
+{{range .Pages}}<li><a href="{{typesDir}}/{{.Name}}.html">{{.Name}}</a>{{with summary .Description}}: {{.}}{{end}}</li>
+{{end}}</ul>
+{{end}}
+{{if .Directives}}<h2>Directives</h2>
+{{range .Directives}}<h3 id="{{.Name}}"><code>@{{.Name}}</code></h3>
+{{template "description" .Description}}
+<p class="kind">{{if .Repeatable}}repeatable, {{end}}on {{join .Locations " | "}}</p>
+{{template "args" .Args}}
+{{end}}{{end}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "page"}}{{template "head" (head .Page.Name "../")}}{{template "nav" .Site}}{{with .Page}}<main>
+<h1>{{.Name}}</h1>
+<p class="kind">{{.Kind}}{{if .Interfaces}} implementing {{range $i, $n := .Interfaces}}{{if $i}}, {{end}}{{typeLink $n $n}}{{end}}{{end}}</p>
+{{template "description" .Description}}
+{{if .SpecifiedBy}}<p>Specified by <a href="{{.SpecifiedBy}}">{{.SpecifiedBy}}</a></p>
+{{end}}
+{{if .Fields}}<h2>Fields</h2>
+{{range .Fields}}<h3 id="{{.Name}}"><code>{{.Name}}</code></h3>
+<p>{{typeLink .TypeName .Type}}</p>
+{{template "deprecated" .Deprecated}}{{template "description" .Description}}{{template "args" .Args}}
+{{end}}{{end}}
+{{if .InputFields}}<h2>Fields</h2>
+{{template "args" .InputFields}}
+{{end}}
+{{if .Values}}<h2>Values</h2>
+<table>
+<tr><th>Value</th><th>Description</th></tr>
+{{range .Values}}<tr id="{{.Name}}"><td><code>{{.Name}}</code></td><td>{{template "deprecated" .Deprecated}}{{template "description" .Description}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .PossibleTypes}}<h2>{{if eq .Kind "interface"}}Implemented by{{else}}Possible types{{end}}</h2>
+<ul>
+{{range .PossibleTypes}}<li>{{typeLink . .}}</li>
+{{end}}</ul>
+{{end}}
+{{if .UsedBy}}<h2>Used by</h2>
+<ul>
+{{range .UsedBy}}<li><a href="{{typesDir}}/{{.Page}}.html{{if .Field}}#{{.Field}}{{end}}"><code>{{.}}</code></a></li>
+{{end}}</ul>
+{{end}}</main>
+</body>
+</html>
+{{end}}{{end}}`
+
+// html returns the HTML pages: index.html and a page per type named after
+// it in the types directory.
+func (site *docSite) html() (map[string]string, error) {
+	t, err := template.New("docs").Funcs(template.FuncMap{
+		"typeLink": func(name, text string) template.HTML {
+			code := "<code>" + template.HTMLEscapeString(text) + "</code>"
+			if !site.hasPage(name) {
+				return template.HTML(code)
+			}
+			return template.HTML(`<a href="` + docsTypesDir + "/" + template.HTMLEscapeString(name) + `.html">` + code + "</a>")
+		},
+		"head": func(title, base string) map[string]string {
+			return map[string]string{"Title": title, "Base": base}
+		},
+		"typesDir": func() string { return docsTypesDir },
+		"summary":  summary,
+		"join":     strings.Join,
+	}).Parse(docsHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := map[string]string{}
+	var b strings.Builder
+	if err := t.ExecuteTemplate(&b, "index", site); err != nil {
+		return nil, err
+	}
+	pages["index.html"] = b.String()
+
+	for name, p := range site.pages {
+		b.Reset()
+		data := struct {
+			Site *docSite
+			Page *docPage
+		}{site, p}
+		if err := t.ExecuteTemplate(&b, "page", data); err != nil {
+			return nil, err
+		}
+		pages[docsTypesDir+"/"+name+".html"] = b.String()
+	}
+	return pages, nil
+}
@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const docsSchema = `"The blog"
+schema {
+  query: Query
+}
+
+type Query {
+  "Find posts"
+  posts(first: Int = 10, status: [Status!] = [PUBLISHED]): [Post!]!
+  node(id: ID!): Node @deprecated(reason: "Use posts")
+}
+
+interface Node {
+  id: ID!
+}
+
+"""
+A post
+of the blog
+"""
+type Post implements Node {
+  id: ID!
+  status: Status
+  published: Date
+}
+
+union SearchResult = Post
+
+enum Status {
+  DRAFT
+  PUBLISHED @deprecated
+}
+
+input PostFilter {
+  "Only these | statuses"
+  status: Status = DRAFT
+}
+
+scalar Date @specifiedBy(url: "https://example.com/date")
+
+directive @cached(ttl: Int) repeatable on FIELD_DEFINITION
+`
+
+func TestDocsMarkdown(t *testing.T) {
+	pages, err := Docs(parseString(t, docsSchema), DocsMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for name := range pages {
+		names = append(names, name)
+	}
+	if len(pages) != 8 {
+		t.Fatalf("unexpected pages %v", names)
+	}
+
+	for name, want := range map[string][]string{
+		"README.md": {
+			"The blog",
+			"- query: [Query](types/Query.md)",
+			"- [Post](types/Post.md): A post\n",
+			"### `@cached`",
+			"_repeatable, on FIELD_DEFINITION_",
+		},
+		"types/Query.md": {
+			"### `posts`\n\n[`[Post!]!`](Post.md)",
+			"| `first` | `Int` | `10` |  |",
+			"| `status` | [`[Status!]`](Status.md) | `[PUBLISHED]` |  |",
+			"> **Deprecated:** Use posts",
+		},
+		"types/Post.md": {
+			"_object type_ implementing [Node](Node.md)\n\n[Schema](../README.md)",
+			"A post\nof the blog",
+			"- [`Query.posts`](Query.md#posts)",
+			"- [`SearchResult`](SearchResult.md)",
+		},
+		"types/Node.md":         {"## Implemented by\n\n- [Post](Post.md)", "- [`Post`](Post.md)", "- [`Query.node`](Query.md#node)"},
+		"types/SearchResult.md": {"## Possible types\n\n- [Post](Post.md)"},
+		"types/Status.md": {
+			"| `PUBLISHED` | **Deprecated:** No longer supported |",
+			"- [`PostFilter.status`](PostFilter.md)",
+			"- [`Query.posts(status:)`](Query.md#posts)",
+		},
+		"types/PostFilter.md": {"| `status` | [`Status`](Status.md) | `DRAFT` | Only these \\| statuses |"},
+		"types/Date.md":       {"Specified by <https://example.com/date>", "- [`Post.published`](Post.md#published)"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(pages[name], w) {
+				t.Errorf("%s: %q not found in\n%s", name, w, pages[name])
+			}
+		}
+	}
+}
+
+func TestDocsHTML(t *testing.T) {
+	dir := t.TempDir()
+	n, err := WriteDocs(dir, parseString(t, docsSchema), DocsHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 {
+		t.Fatalf("expected 8 pages, got %d", n)
+	}
+
+	bs, err := os.ReadFile(filepath.Join(dir, "types", "Post.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(bs)
+	for _, w := range []string{
+		`<base href="../">`,
+		`<a href="index.html"><strong>Schema</strong></a>`,
+		`<a href="types/Node.html"><code>Node</code></a>`,
+		`<a href="types/Query.html#posts"><code>Query.posts</code></a>`,
+		`<a href="types/Status.html"><code>Status</code></a>`,
+		`<li><a href="types/SearchResult.html">SearchResult</a></li>`,
+	} {
+		if !strings.Contains(page, w) {
+			t.Errorf("%q not found in\n%s", w, page)
+		}
+	}
+
+	bs, err = os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index := string(bs); strings.Contains(index, "<base") || !strings.Contains(index, `<a href="types/Post.html">Post</a>: A post`) {
+		t.Errorf("unexpected index\n%s", index)
+	}
+
+	if _, err := Docs(&Schema{}, "pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDocsIndexCollision(t *testing.T) {
+	s := parseString(t, "type Query { readme: README }\ntype README { index: index }\ntype index { id: ID }\n")
+
+	for format, index := range map[DocsFormat]string{DocsMarkdown: "README.md", DocsHTML: "index.html"} {
+		pages, err := Docs(s, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pages) != 4 {
+			t.Errorf("%s: expected 4 pages, got %d", format, len(pages))
+		}
+		if !strings.Contains(pages[index], "Query") {
+			t.Errorf("%s: expected the index not to be overwritten by a type, got\n%s", format, pages[index])
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Extract merges the GraphQL files like Merge and generates a schema which
+// contains only the given root fields and every type they need.
+// Root fields are given as coordinates, e.g. "Query.orders". The parse and
+// merge errors are returned instead of panicking.
+func Extract(indent string, coordinates []string, paths ...string) (ss *string, err error) {
+	defer recoverError(&err)
+
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, nil
+	}
+
+	reportUndefinedTypes(schema)
+
+	sub, err := schema.Extract(coordinates...)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := MergedSchema{Indent: indent}
+	out := ms.WriteSchema(sub)
+	return &out, nil
+}
+
+// Extract returns a copy of the schema which contains only the given root
+// fields and the types, interface implementations and directive definitions
+// they transitively need. The schema itself is left untouched.
+func (s *Schema) Extract(coordinates ...string) (*Schema, error) {
+	selected := map[string]map[string]bool{}
+	for _, c := range coordinates {
+		typ, field, ok := strings.Cut(c, ".")
+		if !ok || typ == "" || field == "" {
+			return nil, fmt.Errorf(`invalid field coordinate "%s", expected Type.field`, c)
+		}
+		if selected[typ] == nil {
+			selected[typ] = map[string]bool{}
+		}
+		selected[typ][field] = true
+	}
+
+	roots := map[string]bool{}
+	for _, r := range s.rootTypeNames() {
+		roots[r] = true
+	}
+
+	for typ, fields := range selected {
+		if !roots[typ] {
+			return nil, fmt.Errorf(`"%s" is not a root operation type`, typ)
+		}
+		for field := range fields {
+			if !s.hasField(typ, field) {
+				return nil, fmt.Errorf(`field "%s.%s" is not defined`, typ, field)
+			}
+		}
+	}
+
+	defined := s.definedTypeNames()
+	empty := s.emptyTypeNames()
+	sub := s.clone()
+
+	for _, t := range sub.Types {
+		if !roots[t.Name] {
+			continue
+		}
+		fields := t.Fields[:0]
+		for _, f := range t.Fields {
+			if selected[t.Name][f.Name] {
+				fields = append(fields, f)
+			}
+		}
+		t.Fields = fields
+	}
+
+	// the directive definitions take part in the reachability, so drop the
+	// unused ones until nothing changes anymore.
+	for {
+		sub.prune(defined, defined, empty)
+
+		used := sub.usedDirectiveNames()
+		dds := sub.DirectiveDefinitions[:0]
+		for _, d := range sub.DirectiveDefinitions {
+			if used[d.Name] {
+				dds = append(dds, d)
+			}
+		}
+		if len(dds) == len(sub.DirectiveDefinitions) {
+			break
+		}
+		sub.DirectiveDefinitions = dds
+	}
+
+	return sub, nil
+}
+
+func (s *Schema) hasField(typ, field string) bool {
+	for _, t := range s.Types {
+		if t.Name != typ {
+			continue
+		}
+		for _, f := range t.Fields {
+			if f.Name == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usedDirectiveNames returns the names of the directives applied anywhere
+// in the schema.
+func (s *Schema) usedDirectiveNames() map[string]bool {
+	used := map[string]bool{}
+	Walk(s, Visitor{KindDirective: {Enter: func(c *Cursor) Action {
+		used[c.Node().(*Directive).Name] = true
+		return SkipChildren
+	}}})
+	return used
+}
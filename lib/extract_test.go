@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	var src = `
+	directive @key(fields: String!) on OBJECT
+	directive @auth(role: Role) on FIELD_DEFINITION
+
+	type Query {
+		orders(status: OrderStatus): [Order!]!
+		users: [User!]! @auth(role: ADMIN)
+	}
+
+	type Mutation {
+		createOrder(input: CreateOrderInput!): Order!
+		deleteUser(id: ID!): Boolean
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	type Order implements Node @key(fields: "id") {
+		id: ID!
+		total: Money
+		lines: [OrderLine!]!
+	}
+
+	type OrderLine {
+		product: Product
+	}
+
+	type Product implements Node {
+		id: ID!
+	}
+
+	type Coupon implements Node {
+		id: ID!
+	}
+
+	type User {
+		id: ID!
+	}
+
+	input CreateOrderInput {
+		lines: [ID!]!
+	}
+
+	enum OrderStatus {
+		OPEN
+		CLOSED
+	}
+
+	enum Role {
+		ADMIN
+	}
+
+	scalar Money
+	scalar Date
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	sub, err := merged.Extract("Query.orders", "Mutation.createOrder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms := MergedSchema{Indent: "  "}
+	out := ms.WriteSchema(sub)
+
+	for _, kept := range []string{"orders(status: OrderStatus)", "createOrder(", "type Order", "type OrderLine", "type Product", "type Coupon", "interface Node", "input CreateOrderInput", "enum OrderStatus", "scalar Money", "directive @key"} {
+		if !strings.Contains(out, kept) {
+			t.Fatalf("%q should be kept:\n%s", kept, out)
+		}
+	}
+	for _, removed := range []string{"users", "deleteUser", "type User", "enum Role", "scalar Date", "directive @auth"} {
+		if strings.Contains(out, removed) {
+			t.Fatalf("%q should be removed:\n%s", removed, out)
+		}
+	}
+
+	if _, err := merged.Extract("Query.missing"); err == nil {
+		t.Fatal("should fail with an undefined field")
+	}
+	if _, err := merged.Extract("Order.id"); err == nil {
+		t.Fatal("should fail with a non root type")
+	}
+}
+
+// conflictingDir returns a directory of GraphQL files which cannot be
+// merged.
+func conflictingDir(t *testing.T) string {
+	dir := t.TempDir()
+	for name, src := range map[string]string{"a.graphql": "type User { id: ID! }", "b.graphql": "type User { id: String }"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExtractConflict(t *testing.T) {
+	if _, err := Extract("  ", []string{"Query.user"}, conflictingDir(t)); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+}
@@ -0,0 +1,272 @@
+package lib
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the files which list, in the gitignore syntax,
+// the files and directories to leave out of the directory they are in.
+const IgnoreFile = ".gqlmergeignore"
+
+// Filter selects the GraphQL files found in the walked paths by glob
+// patterns matched against the slash separated path of the file relative
+// to the walked path, e.g. "schema/**/*.graphql".
+//
+// "*" matches any part of a name and "**" any number of directories. A
+// pattern without a slash matches the name of the file or of one of its
+// directories at any depth, e.g. "__fixtures__", unless it starts with a
+// slash, e.g. "/schema.graphql". A pattern matching a directory matches
+// every file in it.
+type Filter struct {
+	// Include selects the files, or every file when empty.
+	Include []string
+	// Exclude leaves out the files, even if they are included.
+	Exclude []string
+	// Extensions are the extensions of the GraphQL files, ".graphql" and
+	// ".gql" when empty.
+	Extensions []string
+}
+
+// Match reports whether the file, relative to the walked path, is
+// selected.
+func (f *Filter) Match(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if len(f.Include) > 0 && !matchAnyGlob(f.Include, rel) {
+		return false
+	}
+	return !matchAnyGlob(f.Exclude, rel)
+}
+
+// isSchemaFile reports whether the file has one of the extensions.
+func (f *Filter) isSchemaFile(p string) bool {
+	if f == nil || len(f.Extensions) == 0 {
+		return isGraphQLFile(p)
+	}
+	ext := filepath.Ext(p)
+	for _, e := range f.Extensions {
+		if ext == "."+strings.TrimPrefix(e, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// accept reports whether the file found in the walked root is selected.
+// The names are slash separated, as in an fs.FS.
+func (f *Filter) accept(root, name string) bool {
+	if f == nil || name == root {
+		return true
+	}
+	return f.Match(relName(root, name))
+}
+
+// skip reports whether the directory found in the walked root is
+// excluded, so that none of its files needs to be walked.
+func (f *Filter) skip(root, dir string) bool {
+	if f == nil || dir == root {
+		return false
+	}
+	return matchAnyGlob(f.Exclude, relName(root, dir))
+}
+
+// relName returns the slash separated name relative to the directory in
+// which it is found.
+func relName(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	return strings.TrimPrefix(name, dir+"/")
+}
+
+// walkSchemaFiles calls fn with the GraphQL files found in root which are
+// selected by the filter, every one when it is nil, and not ignored by an
+// ignore file, in lexical order. A file given as the root itself is only
+// checked for its extension, which can be .json for an introspection
+// result.
+func walkSchemaFiles(filter *Filter, root string, fn func(p string, info os.FileInfo) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if !filter.isSchemaFile(root) && !isIntrospectionFile(root) {
+			return nil
+		}
+		return fn(root, info)
+	}
+
+	return walkFS(filter, os.DirFS(root), ".", func(name string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(filepath.Join(root, filepath.FromSlash(name)), info)
+	})
+}
+
+// walkFS calls fn with the GraphQL files found in the root of fsys like
+// walkSchemaFiles.
+func walkFS(filter *Filter, fsys fs.FS, root string, fn func(name string, d fs.DirEntry) error) error {
+	ig := ignorer{}
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if name != root && (filter.skip(root, name) || ig.ignored(root, name, true)) {
+				return fs.SkipDir
+			}
+			return ig.load(fsys, name)
+		}
+
+		if !filter.isSchemaFile(name) {
+			return nil
+		}
+		if name != root && (!filter.accept(root, name) || ig.ignored(root, name, false)) {
+			return nil
+		}
+		return fn(name, d)
+	})
+}
+
+// ignoreRule is a line of an ignore file.
+type ignoreRule struct {
+	// pattern is relative to the directory of the ignore file
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// ignorer holds the rules of the ignore files by directory.
+type ignorer map[string][]ignoreRule
+
+// load reads the ignore file of the directory, if any.
+func (ig ignorer) load(fsys fs.FS, dir string) error {
+	bs, err := fs.ReadFile(fsys, path.Join(dir, IgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ig[dir] = parseIgnore(string(bs))
+	return nil
+}
+
+// parseIgnore parses the gitignore syntax: a "#" starts a comment, a "!"
+// includes again what is ignored by the previous lines, a trailing slash
+// matches only directories and a pattern with a slash elsewhere is
+// relative to the directory of the file instead of matching at any depth.
+func parseIgnore(s string) []ignoreRule {
+	rules := []ignoreRule{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// ignored reports whether the file or directory found in the walked root
+// is ignored. The rules of the inner directories override the outer ones,
+// and the last matching rule of a file wins.
+func (ig ignorer) ignored(root, name string, dir bool) bool {
+	dirs := []string{}
+	for d := path.Dir(name); ; d = path.Dir(d) {
+		dirs = append(dirs, d)
+		if d == root || d == "." || d == "/" {
+			break
+		}
+	}
+
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel := relName(dirs[i], name)
+		for _, r := range ig[dirs[i]] {
+			if r.dirOnly && !dir {
+				continue
+			}
+			if matchGlob(r.pattern, rel) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+		if strings.HasPrefix(p, "/") {
+			p = p[1:]
+		} else if !strings.Contains(strings.TrimSuffix(p, "/"), "/") {
+			p = "**/" + p
+		}
+		p = strings.TrimSuffix(p, "/")
+		if matchGlob(p, name) || matchGlob(p+"/**", name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether the slash separated name matches the pattern,
+// where "**" matches any number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(ps, ns []string) bool {
+	for len(ps) > 0 {
+		if ps[0] == "**" {
+			ps = ps[1:]
+			if len(ps) == 0 {
+				return true
+			}
+			for i := 0; i <= len(ns); i++ {
+				if matchSegments(ps, ns[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(ns) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(ps[0], ns[0]); !ok {
+			return false
+		}
+		ps, ns = ps[1:], ns[1:]
+	}
+	return len(ns) == 0
+}
@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := &Filter{
+		Include: []string{"schema/**/*.graphql", "extra.gql"},
+		Exclude: []string{"__fixtures__", "/schema/generated.graphql"},
+	}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"schema/user.graphql", true},
+		{"schema/a/b/user.graphql", true},
+		{"schema/user.gql", false},
+		{"extra.gql", true},
+		{"nested/extra.gql", true},
+		{"other/user.graphql", false},
+		{"schema/__fixtures__/user.graphql", false},
+		{"schema/a/__fixtures__/b/user.graphql", false},
+		{"schema/generated.graphql", false},
+		{"schema/a/generated.graphql", true},
+	}
+	for _, tt := range tests {
+		if got := f.Match(tt.name); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	if !(&Filter{}).Match("any/file.graphql") {
+		t.Error("an empty filter should match every file")
+	}
+}
+
+func TestMergeFilterAndConflictPolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("schema/query.graphql", "type Query { user: User }")
+	write("schema/user.graphql", "type User { id: ID! }")
+	write("schema/__fixtures__/user.graphql", "type User { id: String }")
+
+	if s := mergePaths(MergeOptions{Filter: &Filter{Exclude: []string{"__fixtures__"}}}, dir); s == nil || len(s.Types) != 2 {
+		t.Fatalf("expected the fixtures to be left out, got %+v", s)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected a conflict error")
+			}
+		}()
+		mergePaths(MergeOptions{}, dir)
+	}()
+
+	// the files are walked in lexical order, so the fixture comes first
+	s := mergePaths(MergeOptions{OnConflict: ConflictKeepFirst}, dir)
+	if s == nil || len(s.Types) != 2 {
+		t.Fatalf("expected the first User to be kept, got %+v", s)
+	}
+	for _, ty := range s.Types {
+		if ty.Name == "User" && (len(ty.Fields) != 1 || ty.Fields[0].Type != "String") {
+			t.Errorf("expected the first User to be kept, got %+v", ty.Fields[0])
+		}
+	}
+}
+
+func TestWalkSchemaFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("query.graphqls", "")
+	write("user.graphql", "")
+	write("generated/schema.graphql", "")
+	write("sub/a.graphql", "")
+	write("sub/b.graphql", "")
+	write("sub/deep/c.graphql", "")
+	write(IgnoreFile, "# generated by the build\ngenerated/\n/sub/*.graphql\n")
+	write("sub/"+IgnoreFile, "!b.graphql\ndeep\n")
+
+	walk := func(f *Filter) []string {
+		found := []string{}
+		err := walkSchemaFiles(f, dir, func(p string, info os.FileInfo) error {
+			rel, err := filepath.Rel(dir, p)
+			found = append(found, filepath.ToSlash(rel))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+
+	if found := walk(nil); !reflect.DeepEqual(found, []string{"sub/b.graphql", "user.graphql"}) {
+		t.Errorf("unexpected files %v", found)
+	}
+
+	f := &Filter{Extensions: []string{"graphqls", ".graphql"}, Exclude: []string{"user.graphql"}}
+	if found := walk(f); !reflect.DeepEqual(found, []string{"query.graphqls", "sub/b.graphql"}) {
+		t.Errorf("unexpected files %v", found)
+	}
+}
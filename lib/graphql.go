@@ -1,9 +1,5 @@
 package lib
 
-import (
-	"os"
-)
-
 type BaseFileInfo struct {
 	Filename string
 	Line     int
@@ -11,7 +7,7 @@ type BaseFileInfo struct {
 }
 
 type Schema struct {
-	Files                []*os.File
+	Sources              []*Source `json:"-"`
 	SchemaDefinitions    []*SchemaDefinition
 	Types                []*Type
 	Scalars              []*Scalar
@@ -20,6 +16,9 @@ type Schema struct {
 	Unions               []*Union
 	Inputs               []*Input
 	DirectiveDefinitions []*DirectiveDefinition
+
+	// onConflict is the conflict policy of the merge passes
+	onConflict ConflictPolicy
 }
 
 type SchemaDefinition struct {
@@ -47,6 +46,7 @@ type DirectiveArg struct {
 }
 
 type Directive struct {
+	BaseFileInfo
 	Name          string
 	DirectiveArgs []*DirectiveArg
 	Descriptions  *[]string
@@ -64,6 +64,7 @@ type Type struct {
 }
 
 type Arg struct {
+	BaseFileInfo
 	Name          string
 	Type          string
 	DefaultValues *[]string // in case of default values e.g. admin(role: Role = ADMIN): Admin!
@@ -97,6 +98,7 @@ type Scalar struct {
 }
 
 type EnumValue struct {
+	BaseFileInfo
 	Name         string
 	Directives   []*Directive
 	Descriptions *[]string
@@ -0,0 +1,245 @@
+package lib
+
+import (
+	"strings"
+)
+
+type typeKind int
+
+const (
+	kindUndefined typeKind = iota
+	kindObject
+	kindInterface
+	kindUnion
+	kindEnum
+	kindInput
+	kindScalar
+)
+
+func (k typeKind) String() string {
+	switch k {
+	case kindObject:
+		return "object type"
+	case kindInterface:
+		return "interface"
+	case kindUnion:
+		return "union"
+	case kindEnum:
+		return "enum"
+	case kindInput:
+		return "input object"
+	case kindScalar:
+		return "scalar"
+	default:
+		return "undefined type"
+	}
+}
+
+// builtInScalars are the scalars every GraphQL service provides.
+var builtInScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+func isBuiltInScalar(name string) bool {
+	for _, n := range builtInScalars {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaIndex looks up the definitions of a schema by name.
+type schemaIndex struct {
+	types      map[string]*Type
+	interfaces map[string]*Interface
+	unions     map[string]*Union
+	enums      map[string]*Enum
+	inputs     map[string]*Input
+	scalars    map[string]*Scalar
+	directives map[string]*DirectiveDefinition
+}
+
+func (s *Schema) index() *schemaIndex {
+	x := &schemaIndex{
+		types:      map[string]*Type{},
+		interfaces: map[string]*Interface{},
+		unions:     map[string]*Union{},
+		enums:      map[string]*Enum{},
+		inputs:     map[string]*Input{},
+		scalars:    map[string]*Scalar{},
+		directives: map[string]*DirectiveDefinition{},
+	}
+	for _, t := range s.Types {
+		x.types[t.Name] = t
+	}
+	for _, i := range s.Interfaces {
+		x.interfaces[i.Name] = i
+	}
+	for _, u := range s.Unions {
+		x.unions[u.Name] = u
+	}
+	for _, e := range s.Enums {
+		x.enums[e.Name] = e
+	}
+	for _, i := range s.Inputs {
+		x.inputs[i.Name] = i
+	}
+	for _, c := range s.Scalars {
+		x.scalars[c.Name] = c
+	}
+	for _, d := range s.DirectiveDefinitions {
+		x.directives[d.Name] = d
+	}
+	return x
+}
+
+// kind returns the kind of the named type. The built-in scalars are
+// scalars even if they are not defined in the schema.
+func (x *schemaIndex) kind(name string) typeKind {
+	if _, ok := x.types[name]; ok {
+		return kindObject
+	}
+	if _, ok := x.interfaces[name]; ok {
+		return kindInterface
+	}
+	if _, ok := x.unions[name]; ok {
+		return kindUnion
+	}
+	if _, ok := x.enums[name]; ok {
+		return kindEnum
+	}
+	if _, ok := x.inputs[name]; ok {
+		return kindInput
+	}
+	if _, ok := x.scalars[name]; ok || isBuiltInScalar(name) {
+		return kindScalar
+	}
+	return kindUndefined
+}
+
+func (x *schemaIndex) isInputType(name string) bool {
+	switch x.kind(name) {
+	case kindScalar, kindEnum, kindInput:
+		return true
+	}
+	return false
+}
+
+func (x *schemaIndex) isOutputType(name string) bool {
+	switch x.kind(name) {
+	case kindScalar, kindEnum, kindObject, kindInterface, kindUnion:
+		return true
+	}
+	return false
+}
+
+// typeNames returns the names of the defined types and the built-in
+// scalars.
+func (x *schemaIndex) typeNames() []string {
+	names := append([]string{}, builtInScalars...)
+	for n := range x.types {
+		names = append(names, n)
+	}
+	for n := range x.interfaces {
+		names = append(names, n)
+	}
+	for n := range x.unions {
+		names = append(names, n)
+	}
+	for n := range x.enums {
+		names = append(names, n)
+	}
+	for n := range x.inputs {
+		names = append(names, n)
+	}
+	for n := range x.scalars {
+		if !isBuiltInScalar(n) {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// implements reports whether the object type implements the interface.
+func (x *schemaIndex) implements(object, iface string) bool {
+	t, ok := x.types[object]
+	if !ok {
+		return false
+	}
+	for _, it := range t.ImplTypes {
+		if it == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// typeRef is a type reference such as [User!]!. The model supports a
+// single level of list.
+type typeRef struct {
+	Name     string
+	Null     bool
+	IsList   bool
+	ListNull bool
+}
+
+func fieldTypeRef(f *Field) typeRef {
+	return typeRef{Name: f.Type, Null: f.Null, IsList: f.IsList, ListNull: f.IsListNull}
+}
+
+func argTypeRef(a *Arg) typeRef {
+	return typeRef{Name: a.Type, Null: a.Null, IsList: a.IsList, ListNull: a.IsListNull}
+}
+
+// nonNull reports whether the outermost type is non-null.
+func (t typeRef) nonNull() bool {
+	if t.IsList {
+		return !t.ListNull
+	}
+	return !t.Null
+}
+
+func (t typeRef) String() string {
+	var b strings.Builder
+	if t.IsList {
+		b.WriteString("[")
+	}
+	b.WriteString(t.Name)
+	if !t.Null {
+		b.WriteString("!")
+	}
+	if t.IsList {
+		b.WriteString("]")
+		if !t.ListNull {
+			b.WriteString("!")
+		}
+	}
+	return b.String()
+}
+
+// isSubType reports whether a field of type sub can stand for a field of
+// type super, e.g. in an object type implementing an interface.
+func (x *schemaIndex) isSubType(sub, super typeRef) bool {
+	if sub.IsList != super.IsList {
+		return false
+	}
+	if sub.IsList && sub.ListNull && !super.ListNull {
+		return false
+	}
+	if sub.Null && !super.Null {
+		return false
+	}
+	if sub.Name == super.Name {
+		return true
+	}
+	switch x.kind(super.Name) {
+	case kindInterface:
+		return x.implements(sub.Name, super.Name)
+	case kindUnion:
+		for _, m := range x.unions[super.Name].Types {
+			if m == sub.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
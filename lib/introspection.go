@@ -0,0 +1,532 @@
+package lib
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Introspection is the result of the introspection query of GraphiQL and
+// the client code generators, {"data": {"__schema": ...}}, with the
+// deprecated fields, arguments and values included.
+type Introspection struct {
+	Data IntrospectionData `json:"data"`
+}
+
+type IntrospectionData struct {
+	Schema *IntrospectionSchema `json:"__schema"`
+}
+
+type IntrospectionSchema struct {
+	Description      *string                   `json:"description"`
+	QueryType        *IntrospectionTypeRef     `json:"queryType"`
+	MutationType     *IntrospectionTypeRef     `json:"mutationType"`
+	SubscriptionType *IntrospectionTypeRef     `json:"subscriptionType"`
+	Types            []*IntrospectionType      `json:"types"`
+	Directives       []*IntrospectionDirective `json:"directives"`
+}
+
+// IntrospectionType is a named type. The lists which do not apply to its
+// kind are nil, i.e. null in JSON.
+type IntrospectionType struct {
+	Kind           string                     `json:"kind"`
+	Name           string                     `json:"name"`
+	Description    *string                    `json:"description"`
+	SpecifiedByURL *string                    `json:"specifiedByURL"`
+	Fields         []*IntrospectionField      `json:"fields"`
+	InputFields    []*IntrospectionInputValue `json:"inputFields"`
+	Interfaces     []*IntrospectionTypeRef    `json:"interfaces"`
+	EnumValues     []*IntrospectionEnumValue  `json:"enumValues"`
+	PossibleTypes  []*IntrospectionTypeRef    `json:"possibleTypes"`
+}
+
+// IntrospectionTypeRef is a reference to a type, e.g. [Post!]! is a
+// NON_NULL of a LIST of a NON_NULL of the OBJECT Post.
+type IntrospectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   *string               `json:"name"`
+	OfType *IntrospectionTypeRef `json:"ofType"`
+}
+
+type IntrospectionField struct {
+	Name              string                     `json:"name"`
+	Description       *string                    `json:"description"`
+	Args              []*IntrospectionInputValue `json:"args"`
+	Type              *IntrospectionTypeRef      `json:"type"`
+	IsDeprecated      bool                       `json:"isDeprecated"`
+	DeprecationReason *string                    `json:"deprecationReason"`
+}
+
+// IntrospectionInputValue is an argument or an input field. The default
+// value is written as in the schema, e.g. "[ADMIN, USER]".
+type IntrospectionInputValue struct {
+	Name              string                `json:"name"`
+	Description       *string               `json:"description"`
+	Type              *IntrospectionTypeRef `json:"type"`
+	DefaultValue      *string               `json:"defaultValue"`
+	IsDeprecated      bool                  `json:"isDeprecated"`
+	DeprecationReason *string               `json:"deprecationReason"`
+}
+
+type IntrospectionEnumValue struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type IntrospectionDirective struct {
+	Name         string                     `json:"name"`
+	Description  *string                    `json:"description"`
+	IsRepeatable bool                       `json:"isRepeatable"`
+	Locations    []string                   `json:"locations"`
+	Args         []*IntrospectionInputValue `json:"args"`
+}
+
+// introspectionTypesSDL defines the types of the introspection system,
+// which are part of the types of every schema.
+const introspectionTypesSDL = `
+type __Schema {
+  description: String
+  types: [__Type!]!
+  queryType: __Type!
+  mutationType: __Type
+  subscriptionType: __Type
+  directives: [__Directive!]!
+}
+
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+  ofType: __Type
+  specifiedByURL: String
+}
+
+enum __TypeKind {
+  SCALAR
+  OBJECT
+  INTERFACE
+  UNION
+  ENUM
+  INPUT_OBJECT
+  LIST
+  NON_NULL
+}
+
+type __Field {
+  name: String!
+  description: String
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+  defaultValue: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __Directive {
+  name: String!
+  description: String
+  locations: [__DirectiveLocation!]!
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  isRepeatable: Boolean!
+}
+
+enum __DirectiveLocation {
+  QUERY
+  MUTATION
+  SUBSCRIPTION
+  FIELD
+  FRAGMENT_DEFINITION
+  FRAGMENT_SPREAD
+  INLINE_FRAGMENT
+  VARIABLE_DEFINITION
+  SCHEMA
+  SCALAR
+  OBJECT
+  FIELD_DEFINITION
+  ARGUMENT_DEFINITION
+  INTERFACE
+  UNION
+  ENUM
+  ENUM_VALUE
+  INPUT_OBJECT
+  INPUT_FIELD_DEFINITION
+}
+`
+
+var (
+	builtInDefinitionsOnce sync.Once
+	builtIns               *Schema
+)
+
+// builtInDefinitions returns the introspection types, the built-in scalars
+// and the built-in directives as a schema.
+func builtInDefinitions() *Schema {
+	builtInDefinitionsOnce.Do(func() {
+		s := &Schema{}
+		s.Parse(NewParser(strings.NewReader(introspectionTypesSDL+builtInDirectivesSDL), "<built-in>"))
+		for _, name := range builtInScalars {
+			s.Scalars = append(s.Scalars, &Scalar{Name: name})
+		}
+		builtIns = s
+	})
+	return builtIns
+}
+
+// Introspect returns the introspection result of the schema, with the
+// built-in scalars, directives and the introspection types along with the
+// definitions of the schema, sorted by name. A reference to an undefined
+// type is an error, as its kind is unknown.
+func Introspect(s *Schema) (i *Introspection, err error) {
+	defer recoverError(&err)
+
+	b := builtInDefinitions()
+	x := s.index()
+	all := &Schema{
+		Types:                append(append([]*Type{}, s.Types...), b.Types...),
+		Scalars:              append([]*Scalar{}, s.Scalars...),
+		Enums:                append(append([]*Enum{}, s.Enums...), b.Enums...),
+		Interfaces:           s.Interfaces,
+		Unions:               s.Unions,
+		Inputs:               s.Inputs,
+		DirectiveDefinitions: append([]*DirectiveDefinition{}, s.DirectiveDefinitions...),
+	}
+	for _, sc := range b.Scalars {
+		if _, ok := x.scalars[sc.Name]; !ok {
+			all.Scalars = append(all.Scalars, sc)
+		}
+	}
+	for _, d := range b.DirectiveDefinitions {
+		if _, ok := x.directives[d.Name]; !ok {
+			all.DirectiveDefinitions = append(all.DirectiveDefinitions, d)
+		}
+	}
+
+	in := &introspector{x: all.index()}
+	return &Introspection{Data: IntrospectionData{Schema: in.schema(s, all)}}, nil
+}
+
+// MarshalIntrospection returns the introspection result of the schema as
+// JSON.
+func MarshalIntrospection(s *Schema) ([]byte, error) {
+	i, err := Introspect(s)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bs, '\n'), nil
+}
+
+type introspector struct {
+	x *schemaIndex
+}
+
+func (in *introspector) schema(s, all *Schema) *IntrospectionSchema {
+	is := &IntrospectionSchema{
+		Types:      []*IntrospectionType{},
+		Directives: []*IntrospectionDirective{},
+	}
+	if len(s.SchemaDefinitions) > 0 {
+		is.Description = introspectionDescription(s.SchemaDefinitions[0].Descriptions)
+	}
+	root := func(op string) *IntrospectionTypeRef {
+		if name := s.rootOperation(op); name != "" {
+			return in.named(name)
+		}
+		return nil
+	}
+	is.QueryType = root("query")
+	is.MutationType = root("mutation")
+	is.SubscriptionType = root("subscription")
+
+	for _, t := range all.Types {
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:        "OBJECT",
+			Name:        t.Name,
+			Description: introspectionDescription(t.Descriptions),
+			Fields:      in.fields(t.Fields),
+			Interfaces:  in.namedList(t.ImplTypes),
+		})
+	}
+	for _, sc := range all.Scalars {
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:           "SCALAR",
+			Name:           sc.Name,
+			Description:    introspectionDescription(sc.Descriptions),
+			SpecifiedByURL: directiveArgString(sc.Directives, "specifiedBy", "url"),
+		})
+	}
+	for _, e := range all.Enums {
+		values := []*IntrospectionEnumValue{}
+		for _, v := range e.EnumValues {
+			reason := deprecation(v.Directives)
+			values = append(values, &IntrospectionEnumValue{
+				Name:              v.Name,
+				Description:       introspectionDescription(v.Descriptions),
+				IsDeprecated:      reason != nil,
+				DeprecationReason: reason,
+			})
+		}
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:        "ENUM",
+			Name:        e.Name,
+			Description: introspectionDescription(e.Descriptions),
+			EnumValues:  values,
+		})
+	}
+	for _, i := range all.Interfaces {
+		possible := []string{}
+		for _, t := range all.Types {
+			if in.x.implements(t.Name, i.Name) {
+				possible = append(possible, t.Name)
+			}
+		}
+		sort.Strings(possible)
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:          "INTERFACE",
+			Name:          i.Name,
+			Description:   introspectionDescription(i.Descriptions),
+			Fields:        in.fields(i.Fields),
+			Interfaces:    []*IntrospectionTypeRef{},
+			PossibleTypes: in.namedList(possible),
+		})
+	}
+	for _, u := range all.Unions {
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:          "UNION",
+			Name:          u.Name,
+			Description:   introspectionDescription(u.Descriptions),
+			PossibleTypes: in.namedList(u.Types),
+		})
+	}
+	for _, i := range all.Inputs {
+		fields := []*IntrospectionInputValue{}
+		for _, f := range i.Fields {
+			fields = append(fields, in.inputValue(f.Name, f.Descriptions, fieldTypeRef(f), f.DefaultValues, f.Directives))
+		}
+		is.Types = append(is.Types, &IntrospectionType{
+			Kind:        "INPUT_OBJECT",
+			Name:        i.Name,
+			Description: introspectionDescription(i.Descriptions),
+			InputFields: fields,
+		})
+	}
+	sort.SliceStable(is.Types, func(i, j int) bool { return is.Types[i].Name < is.Types[j].Name })
+
+	for _, d := range all.DirectiveDefinitions {
+		is.Directives = append(is.Directives, &IntrospectionDirective{
+			Name:         d.Name,
+			Description:  introspectionDescription(d.Descriptions),
+			IsRepeatable: d.Repeatable,
+			Locations:    nonNil(d.Locations),
+			Args:         in.args(d.Args),
+		})
+	}
+	sort.SliceStable(is.Directives, func(i, j int) bool { return is.Directives[i].Name < is.Directives[j].Name })
+
+	return is
+}
+
+func (in *introspector) fields(fs []*Field) []*IntrospectionField {
+	ifs := make([]*IntrospectionField, 0, len(fs))
+	for _, f := range fs {
+		reason := deprecation(f.Directives)
+		ifs = append(ifs, &IntrospectionField{
+			Name:              f.Name,
+			Description:       introspectionDescription(f.Descriptions),
+			Args:              in.args(f.Args),
+			Type:              in.typeRef(fieldTypeRef(f)),
+			IsDeprecated:      reason != nil,
+			DeprecationReason: reason,
+		})
+	}
+	return ifs
+}
+
+func (in *introspector) args(as []*Arg) []*IntrospectionInputValue {
+	ias := make([]*IntrospectionInputValue, 0, len(as))
+	for _, a := range as {
+		ias = append(ias, in.inputValue(a.Name, a.Descriptions, argTypeRef(a), a.DefaultValues, a.Directives))
+	}
+	return ias
+}
+
+func (in *introspector) inputValue(name string, descriptions *[]string, t typeRef, defaults *[]string, ds []*Directive) *IntrospectionInputValue {
+	reason := deprecation(ds)
+	iv := &IntrospectionInputValue{
+		Name:              name,
+		Description:       introspectionDescription(descriptions),
+		Type:              in.typeRef(t),
+		IsDeprecated:      reason != nil,
+		DeprecationReason: reason,
+	}
+	if defaults != nil {
+		if v := parseDefaultValue(*defaults); v != nil {
+			s := v.String()
+			iv.DefaultValue = &s
+		}
+	}
+	return iv
+}
+
+func (in *introspector) typeRef(t typeRef) *IntrospectionTypeRef {
+	ref := in.named(t.Name)
+	if !t.Null {
+		ref = &IntrospectionTypeRef{Kind: "NON_NULL", OfType: ref}
+	}
+	if t.IsList {
+		ref = &IntrospectionTypeRef{Kind: "LIST", OfType: ref}
+		if !t.ListNull {
+			ref = &IntrospectionTypeRef{Kind: "NON_NULL", OfType: ref}
+		}
+	}
+	return ref
+}
+
+// named returns the reference to a named type, which must be defined.
+func (in *introspector) named(name string) *IntrospectionTypeRef {
+	kind := ""
+	switch in.x.kind(name) {
+	case kindObject:
+		kind = "OBJECT"
+	case kindInterface:
+		kind = "INTERFACE"
+	case kindUnion:
+		kind = "UNION"
+	case kindEnum:
+		kind = "ENUM"
+	case kindInput:
+		kind = "INPUT_OBJECT"
+	case kindScalar:
+		kind = "SCALAR"
+	default:
+		errorf("cannot introspect the undefined type %q", name)
+	}
+	n := name
+	return &IntrospectionTypeRef{Kind: kind, Name: &n}
+}
+
+func (in *introspector) namedList(names []string) []*IntrospectionTypeRef {
+	refs := make([]*IntrospectionTypeRef, 0, len(names))
+	for _, n := range names {
+		refs = append(refs, in.named(n))
+	}
+	return refs
+}
+
+// defaultDeprecationReason is the default value of the reason argument of
+// @deprecated.
+const defaultDeprecationReason = "No longer supported"
+
+// deprecation returns the reason of @deprecated, or nil when the element is
+// not deprecated.
+func deprecation(ds []*Directive) *string {
+	for _, d := range ds {
+		if d.Name == "deprecated" {
+			if reason := directiveArgString(ds, "deprecated", "reason"); reason != nil {
+				return reason
+			}
+			reason := defaultDeprecationReason
+			return &reason
+		}
+	}
+	return nil
+}
+
+// directiveArgString returns the value of a string argument of an applied
+// directive, or nil.
+func directiveArgString(ds []*Directive, directive, arg string) *string {
+	for _, d := range ds {
+		if d.Name != directive {
+			continue
+		}
+		for _, a := range d.DirectiveArgs {
+			if a.Name == arg && !a.IsList && len(a.Value) == 1 && strings.HasPrefix(a.Value[0], `"`) {
+				s := stringValue(a.Value[0])
+				return &s
+			}
+		}
+	}
+	return nil
+}
+
+// introspectionDescription returns the value of the description, or nil
+// when there is none.
+func introspectionDescription(descriptions *[]string) *string {
+	d := descriptionString(descriptions)
+	if d == "" {
+		return nil
+	}
+	s := stringValue(d)
+	return &s
+}
+
+// stringValue returns the value of a string or block string literal.
+func stringValue(raw string) string {
+	if strings.HasPrefix(raw, `"""`) {
+		return blockStringValue(strings.TrimSuffix(strings.TrimPrefix(raw, `"""`), `"""`))
+	}
+
+	// the escape sequences of GraphQL are the ones of JSON
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return strings.Trim(raw, `"`)
+	}
+	return s
+}
+
+// blockStringValue removes the common indentation and the leading and
+// trailing blank lines of a block string, as in the specification.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+
+	common := -1
+	for _, l := range lines[1:] {
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < len(l) && (common < 0 || indent < common) {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.ReplaceAll(strings.Join(lines, "\n"), `\"""`, `"""`)
+}
@@ -0,0 +1,325 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// isIntrospectionFile reports whether the file is a JSON file, which is
+// read as an introspection result when given as a path.
+func isIntrospectionFile(p string) bool {
+	return strings.EqualFold(filepath.Ext(p), ".json")
+}
+
+// isIntrospectionJSON reports whether the content is JSON, as opposed to
+// the SDL which cannot start with a brace, without consuming it.
+func isIntrospectionJSON(r *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		bs, _ := r.Peek(n)
+		if len(bs) < n {
+			return false
+		}
+		c := rune(bs[n-1])
+		if c == '{' {
+			return true
+		}
+		// the bytes of a byte order mark are skipped as well
+		if !unicode.IsSpace(c) && c != 0xEF && c != 0xBB && c != 0xBF {
+			return false
+		}
+	}
+}
+
+// parseContent parses the content of a source, either the SDL or an
+// introspection result.
+func parseContent(r io.Reader, name string) *Schema {
+	br := bufio.NewReader(r)
+	if isIntrospectionJSON(br) {
+		return parseIntrospection(br, name)
+	}
+
+	sc := &Schema{}
+	sc.Parse(NewParser(br, name))
+	return sc
+}
+
+// parseIntrospection reads an introspection result, with or without the
+// "data" around "__schema".
+func parseIntrospection(r io.Reader, name string) *Schema {
+	var result struct {
+		Data   *IntrospectionData   `json:"data"`
+		Schema *IntrospectionSchema `json:"__schema"`
+	}
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		errorf("%s: invalid introspection result: %s", name, err)
+	}
+
+	is := result.Schema
+	if result.Data != nil && result.Data.Schema != nil {
+		is = result.Data.Schema
+	}
+	if is == nil {
+		errorf("%s: invalid introspection result: no __schema found", name)
+	}
+
+	s, err := SchemaFromIntrospection(is, name)
+	if err != nil {
+		panic(Error(err.Error()))
+	}
+	return s
+}
+
+// SchemaFromIntrospection converts an introspection result into a schema,
+// e.g. to be merged with MergeSchemas. The built-in scalars and directives
+// and the introspection types are left out. The name stands for the file
+// name in the positions.
+func SchemaFromIntrospection(is *IntrospectionSchema, name string) (s *Schema, err error) {
+	defer recoverError(&err)
+
+	c := &introspectionConverter{name: name}
+	return c.schema(is), nil
+}
+
+type introspectionConverter struct {
+	name string
+}
+
+func (c *introspectionConverter) pos() BaseFileInfo {
+	return BaseFileInfo{Filename: c.name}
+}
+
+func (c *introspectionConverter) schema(is *IntrospectionSchema) *Schema {
+	s := &Schema{}
+
+	// the schema definition is needed only for the root types which are
+	// not named after their operation
+	sd := &SchemaDefinition{BaseFileInfo: c.pos(), Descriptions: introspectionDescriptions(is.Description)}
+	custom := is.Description != nil
+	root := func(ref *IntrospectionTypeRef, op string) *string {
+		if ref == nil || ref.Name == nil {
+			return nil
+		}
+		if *ref.Name != op {
+			custom = true
+		}
+		n := *ref.Name
+		return &n
+	}
+	sd.Query = root(is.QueryType, "Query")
+	sd.Mutation = root(is.MutationType, "Mutation")
+	sd.Subscription = root(is.SubscriptionType, "Subscription")
+	if custom {
+		s.SchemaDefinitions = append(s.SchemaDefinitions, sd)
+	}
+
+	for _, t := range is.Types {
+		if t == nil || strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		switch t.Kind {
+		case "OBJECT":
+			o := &Type{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				Fields:       c.fields(t.Fields),
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			}
+			for _, i := range t.Interfaces {
+				o.ImplTypes = append(o.ImplTypes, c.typeName(i))
+			}
+			o.Impl = len(o.ImplTypes) > 0
+			s.Types = append(s.Types, o)
+		case "INTERFACE":
+			s.Interfaces = append(s.Interfaces, &Interface{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				Fields:       c.fields(t.Fields),
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			})
+		case "UNION":
+			u := &Union{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				Types:        []string{},
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			}
+			for _, p := range t.PossibleTypes {
+				u.Types = append(u.Types, c.typeName(p))
+			}
+			s.Unions = append(s.Unions, u)
+		case "ENUM":
+			e := &Enum{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				EnumValues:   []EnumValue{},
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			}
+			for _, v := range t.EnumValues {
+				e.EnumValues = append(e.EnumValues, EnumValue{
+					BaseFileInfo: c.pos(),
+					Name:         v.Name,
+					Directives:   deprecatedDirectives(v.IsDeprecated, v.DeprecationReason),
+					Descriptions: introspectionDescriptions(v.Description),
+				})
+			}
+			s.Enums = append(s.Enums, e)
+		case "INPUT_OBJECT":
+			in := &Input{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				Fields:       []*Field{},
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			}
+			for _, f := range t.InputFields {
+				a := c.arg(f)
+				in.Fields = append(in.Fields, &Field{
+					BaseFileInfo:  a.BaseFileInfo,
+					Name:          a.Name,
+					Args:          []*Arg{},
+					Type:          a.Type,
+					Null:          a.Null,
+					IsList:        a.IsList,
+					IsListNull:    a.IsListNull,
+					DefaultValues: a.DefaultValues,
+					Directives:    a.Directives,
+					Descriptions:  a.Descriptions,
+				})
+			}
+			s.Inputs = append(s.Inputs, in)
+		case "SCALAR":
+			if isBuiltInScalar(t.Name) {
+				continue
+			}
+			sc := &Scalar{
+				BaseFileInfo: c.pos(),
+				Name:         t.Name,
+				Directives:   []*Directive{},
+				Descriptions: introspectionDescriptions(t.Description),
+			}
+			if t.SpecifiedByURL != nil {
+				sc.Directives = append(sc.Directives, NewDirective("specifiedBy").Arg("url", strconv.Quote(*t.SpecifiedByURL)).d)
+			}
+			s.Scalars = append(s.Scalars, sc)
+		default:
+			errorf("%s: unknown kind %q of the type %s", c.name, t.Kind, t.Name)
+		}
+	}
+
+	for _, d := range is.Directives {
+		if d == nil || builtInDirective(d.Name) != nil {
+			continue
+		}
+		dd := &DirectiveDefinition{
+			BaseFileInfo: c.pos(),
+			Name:         d.Name,
+			Args:         []*Arg{},
+			Repeatable:   d.IsRepeatable,
+			Locations:    d.Locations,
+			Descriptions: introspectionDescriptions(d.Description),
+		}
+		for _, a := range d.Args {
+			dd.Args = append(dd.Args, c.arg(a))
+		}
+		s.DirectiveDefinitions = append(s.DirectiveDefinitions, dd)
+	}
+
+	return s
+}
+
+func (c *introspectionConverter) fields(ifs []*IntrospectionField) []*Field {
+	fs := make([]*Field, 0, len(ifs))
+	for _, f := range ifs {
+		t := c.typeRef(f.Type)
+		field := &Field{
+			BaseFileInfo: c.pos(),
+			Name:         f.Name,
+			Args:         []*Arg{},
+			Type:         t.Name,
+			Null:         t.Null,
+			IsList:       t.IsList,
+			IsListNull:   t.ListNull,
+			Directives:   deprecatedDirectives(f.IsDeprecated, f.DeprecationReason),
+			Descriptions: introspectionDescriptions(f.Description),
+		}
+		for _, a := range f.Args {
+			field.Args = append(field.Args, c.arg(a))
+		}
+		fs = append(fs, field)
+	}
+	return fs
+}
+
+func (c *introspectionConverter) arg(iv *IntrospectionInputValue) *Arg {
+	t := c.typeRef(iv.Type)
+	a := &Arg{
+		BaseFileInfo: c.pos(),
+		Name:         iv.Name,
+		Type:         t.Name,
+		Null:         t.Null,
+		IsList:       t.IsList,
+		IsListNull:   t.ListNull,
+		Directives:   deprecatedDirectives(iv.IsDeprecated, iv.DeprecationReason),
+		Descriptions: introspectionDescriptions(iv.Description),
+	}
+	if iv.DefaultValue != nil {
+		a.DefaultValues = &[]string{strings.TrimSpace(*iv.DefaultValue)}
+	}
+	return a
+}
+
+// typeRef returns the type reference, with a single level of list as in
+// the schema.
+func (c *introspectionConverter) typeRef(ref *IntrospectionTypeRef) typeRef {
+	t := typeRef{Null: true}
+	if ref != nil && ref.Kind == "NON_NULL" && ref.OfType != nil && ref.OfType.Kind == "LIST" {
+		ref = ref.OfType
+	} else if ref != nil && ref.Kind == "LIST" {
+		t.ListNull = true
+	}
+	if ref != nil && ref.Kind == "LIST" {
+		t.IsList = true
+		ref = ref.OfType
+	}
+	if ref != nil && ref.Kind == "NON_NULL" {
+		t.Null = false
+		ref = ref.OfType
+	}
+	t.Name = c.typeName(ref)
+	return t
+}
+
+func (c *introspectionConverter) typeName(ref *IntrospectionTypeRef) string {
+	if ref == nil || ref.Name == nil || ref.Kind == "LIST" || ref.Kind == "NON_NULL" {
+		errorf("%s: unsupported type reference, only a single level of list is supported", c.name)
+	}
+	return *ref.Name
+}
+
+func deprecatedDirectives(isDeprecated bool, reason *string) []*Directive {
+	if !isDeprecated {
+		return []*Directive{}
+	}
+	// the default reason is left out, as in the sources
+	r := ""
+	if reason != nil && *reason != defaultDeprecationReason {
+		r = *reason
+	}
+	return []*Directive{deprecated(r).d}
+}
+
+func introspectionDescriptions(d *string) *[]string {
+	if d == nil || *d == "" {
+		return &[]string{}
+	}
+	return descriptions(*d)
+}
@@ -0,0 +1,224 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIntrospect(t *testing.T) {
+	s, err := MergeSchemas(parseString(t, `"""
+  The root
+    query
+"""
+type Query {
+  "Find users"
+  users(first: Int = 10, roles: [Role!] = [ADMIN]): [User!]! @deprecated
+  node(id: ID!): Node
+}
+
+interface Node {
+  id: ID!
+}
+
+type User implements Node {
+  id: ID!
+  role: Role
+}
+
+enum Role {
+  ADMIN
+  USER @deprecated(reason: "Use ADMIN")
+}
+
+scalar Date @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+union Result = User`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := Introspect(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := i.Data.Schema
+	if is.QueryType == nil || *is.QueryType.Name != "Query" || is.MutationType != nil {
+		t.Errorf("unexpected root types %+v %+v", is.QueryType, is.MutationType)
+	}
+
+	types := map[string]*IntrospectionType{}
+	for _, ty := range is.Types {
+		types[ty.Name] = ty
+	}
+	for _, name := range []string{"String", "Boolean", "ID", "__Schema", "__Type", "__TypeKind", "__Directive"} {
+		if types[name] == nil {
+			t.Errorf("expected the built-in type %s", name)
+		}
+	}
+
+	q := types["Query"]
+	if q.Description == nil || *q.Description != "The root\n  query" || q.Interfaces == nil || q.EnumValues != nil {
+		t.Errorf("unexpected Query %+v", q)
+	}
+	users := q.Fields[0]
+	if *users.Description != "Find users" || !users.IsDeprecated || *users.DeprecationReason != "No longer supported" {
+		t.Errorf("unexpected users %+v", users)
+	}
+	if *users.Args[0].DefaultValue != "10" || *users.Args[1].DefaultValue != "[ADMIN]" {
+		t.Errorf("unexpected default values %s %s", *users.Args[0].DefaultValue, *users.Args[1].DefaultValue)
+	}
+	if bs, _ := json.Marshal(users.Type); string(bs) != `{"kind":"NON_NULL","name":null,"ofType":{"kind":"LIST","name":null,"ofType":{"kind":"NON_NULL","name":null,"ofType":{"kind":"OBJECT","name":"User","ofType":null}}}}` {
+		t.Errorf("unexpected type %s", bs)
+	}
+
+	if v := types["Role"].EnumValues[1]; !v.IsDeprecated || *v.DeprecationReason != "Use ADMIN" {
+		t.Errorf("unexpected enum value %+v", v)
+	}
+	if u := types["Date"].SpecifiedByURL; u == nil || *u != "https://tools.ietf.org/html/rfc3339" {
+		t.Errorf("unexpected specifiedByURL %v", u)
+	}
+	if p := types["Node"].PossibleTypes; len(p) != 1 || *p[0].Name != "User" {
+		t.Errorf("unexpected possible types of Node %+v", p)
+	}
+	if p := types["Result"].PossibleTypes; len(p) != 1 || p[0].Kind != "OBJECT" {
+		t.Errorf("unexpected possible types of Result %+v", p)
+	}
+
+	names := []string{}
+	for _, d := range is.Directives {
+		names = append(names, d.Name)
+	}
+	if strings.Join(names, ",") != "deprecated,include,oneOf,skip,specifiedBy" {
+		t.Errorf("unexpected directives %v", names)
+	}
+
+	out := printSchema(t, s, PrintOptions{Format: FormatIntrospection})
+	if !strings.HasPrefix(out, "{\n  \"data\": {\n    \"__schema\": {") {
+		t.Errorf("unexpected introspection JSON\n%s", out[:100])
+	}
+}
+
+func TestIntrospectUndefinedType(t *testing.T) {
+	s := parseString(t, "type Query {\n  user: User\n}")
+	if _, err := Introspect(s); err == nil || !strings.Contains(err.Error(), `"User"`) {
+		t.Errorf("expected an undefined type, got %v", err)
+	}
+	if _, err := Print(s, PrintOptions{Format: FormatIntrospection}); err == nil {
+		t.Error("expected the undefined type to fail the print")
+	}
+}
+
+func TestBlockStringValue(t *testing.T) {
+	for raw, expected := range map[string]string{
+		"\n    Hello,\n      World!\n\n    Yours\n  ": "Hello,\n  World!\n\nYours",
+		"  single line ":             "  single line ",
+		"\n  with \\\"\"\" quotes\n": "with \"\"\" quotes",
+	} {
+		if got := blockStringValue(raw); got != expected {
+			t.Errorf("%q: expected %q, got %q", raw, expected, got)
+		}
+	}
+	if got := stringValue(`"a \"b\" é"`); got != `a "b" é` {
+		t.Errorf("unexpected string value %q", got)
+	}
+}
+
+func parseString(t *testing.T, src string) *Schema {
+	t.Helper()
+	s := &Schema{}
+	s.Parse(NewParser(strings.NewReader(src), "schema.graphql"))
+	return s
+}
+
+func TestIntrospectionInput(t *testing.T) {
+	s := parseString(t, `type Query {
+  "Find users"
+  users(roles: [Role!] = [ADMIN, USER]): [User!]! @deprecated
+}
+
+type User {
+  id: ID!
+}
+
+enum Role {
+  ADMIN
+  USER @deprecated(reason: "Use ADMIN")
+}
+
+scalar Date @specifiedBy(url: "https://example.com/date")`)
+	bs, err := MarshalIntrospection(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the introspection result is merged with the SDL, whatever its name
+	ss, err := MergeReaders("  ",
+		Source{Name: "remote", Reader: strings.NewReader(string(bs))},
+		Source{Name: "local.graphql", Reader: strings.NewReader("type User {\n  name: String\n}")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"  \"Find users\"\n  users(roles: [Role!] = [ADMIN, USER]): [User!]! @deprecated\n",
+		"type User {\n  id: ID!\n  name: String\n}",
+		"  USER @deprecated(reason: \"Use ADMIN\")",
+		"scalar Date @specifiedBy(url: \"https://example.com/date\")",
+	} {
+		if !strings.Contains(*ss, expected) {
+			t.Errorf("expected %q in\n%s", expected, *ss)
+		}
+	}
+	if strings.Contains(*ss, "__Type") || strings.Contains(*ss, "scalar String") || strings.Contains(*ss, "directive @skip") {
+		t.Errorf("unexpected built-in definitions in\n%s", *ss)
+	}
+
+	// the conflicts are reported with the name of the introspection result
+	_, err = MergeReaders("  ",
+		Source{Name: "remote.json", Reader: strings.NewReader(string(bs))},
+		Source{Name: "local.graphql", Reader: strings.NewReader("enum Role {\n  GUEST\n}")},
+	)
+	if err == nil || !strings.Contains(err.Error(), "remote.json") {
+		t.Errorf("expected a conflict with remote.json, got %v", err)
+	}
+
+	// a JSON file is read when given as a path
+	dir := t.TempDir()
+	path := filepath.Join(dir, "remote.json")
+	if err := os.WriteFile(path, bs, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if merged, _, err := MergeSchema(path); err != nil || len(merged.Types) != 2 {
+		t.Errorf("expected the types of remote.json, got %v", err)
+	}
+	// but not when found in a directory, unless .json is an extension
+	if merged, _, err := MergeSchema(dir); err != nil || merged != nil {
+		t.Errorf("expected no schema in the directory, got %v", err)
+	}
+
+	// a result without "data" is read as well
+	bare := `{"__schema": {"queryType": {"name": "Root"}, "types": [{"kind": "OBJECT", "name": "Root", "fields": [{"name": "grid", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "Int"}}}], "interfaces": []}], "directives": []}}`
+	ss, err = MergeStrings("  ", map[string]string{"bare.json": bare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*ss, "schema {\n  query: Root\n") || !strings.Contains(*ss, "type Root {\n  grid: [Int]\n}") {
+		t.Errorf("unexpected schema\n%s", *ss)
+	}
+
+	for _, data := range []string{
+		`{"data": {}}`,
+		`{"__schema": {"types": [{"kind": "OBJECT", "name": "Root", "fields": [{"name": "grid", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "Int"}}}}]}]}}`,
+		`{"__schema": `,
+	} {
+		if _, err := MergeStrings("  ", map[string]string{"invalid.json": data}); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
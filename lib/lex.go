@@ -6,6 +6,8 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"strconv"
+	"strings"
 	"unicode"
 )
 
@@ -153,7 +155,7 @@ type token struct {
 }
 
 func (t *token) String() string {
-	if t.typ == tokNumber {
+	if t.typ == tokNumber && t.num != nil {
 		return fmt.Sprint(t.num)
 	}
 	return *t.text
@@ -163,6 +165,7 @@ type lexer struct {
 	filename string
 	line     int
 	col      int
+	tokLine  int // line where the last token starts
 	rd       io.RuneReader
 	peeking  bool
 	peekRune rune
@@ -182,6 +185,12 @@ func newLexer(rd io.RuneReader, filename string) *lexer {
 var tokens = make(map[string]*token)
 
 func mkToken(typ tokenType, text string) *token {
+	if typ == tokNumber && strings.ContainsAny(text, ".eE") {
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			errorf("bad number syntax: %s", text)
+		}
+		return &token{typ: tokNumber, text: &text}
+	}
 	if typ == tokNumber {
 		var z big.Int
 		num, ok := z.SetString(text, 0)
@@ -250,6 +259,7 @@ func (l *lexer) back(r rune) {
 func (l *lexer) next() *token {
 	for {
 		r := l.read()
+		l.tokLine = l.line
 		switch {
 		case isSpace(r):
 			l.skipSpace()
@@ -374,10 +384,28 @@ func (l *lexer) accum(r rune, valid func(rune) bool) {
 
 func (l *lexer) number(r rune) *token {
 	l.accum(r, isNumber)
+	// fractional part and exponent of a float
+	if l.peek() == '.' {
+		l.buf.WriteRune(l.read())
+		l.digits()
+	}
+	if r := l.peek(); r == 'e' || r == 'E' {
+		l.buf.WriteRune(l.read())
+		if r := l.peek(); r == '+' || r == '-' {
+			l.buf.WriteRune(l.read())
+		}
+		l.digits()
+	}
 	l.endToken()
 	return mkToken(tokNumber, l.buf.String())
 }
 
+func (l *lexer) digits() {
+	for isNumber(l.peek()) {
+		l.buf.WriteRune(l.read())
+	}
+}
+
 func (l *lexer) alphanum(r rune) string {
 	l.accum(r, isAlphanum)
 	l.endToken()
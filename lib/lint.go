@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Rule is a lint rule checking the merged schema.
+type Rule interface {
+	// Name is used in the configuration, the diagnostics and the
+	// suppression comments.
+	Name() string
+	Check(s *Schema, r *LintReporter)
+}
+
+// LintOptions configures Lint.
+//
+// Rules maps a rule name to its severity, "error" or "warning", or to "off"
+// to disable it. The rules which are not listed are reported as errors.
+// Extra rules are run along with the built-in ones.
+type LintOptions struct {
+	Rules map[string]string
+	Extra []Rule
+}
+
+// suppressComment disables all the rules, or the rules listed after it, for
+// the element it is written on or above, e.g.
+//
+//	# gqlmerge-lint-disable field-camel-case, root-field-description
+//	legacy_field: String
+const suppressComment = "gqlmerge-lint-disable"
+
+// LintReporter collects the problems reported by a rule.
+type LintReporter struct {
+	rule        Rule
+	severity    Severity
+	diagnostics Diagnostics
+}
+
+// Report reports a problem of the node, which is one of the definitions of
+// the schema such as *Type, *Field, *Arg or *EnumValue. The problem is
+// ignored if the node has a suppression comment for the rule.
+func (r *LintReporter) Report(node interface{}, format string, args ...interface{}) {
+	pos, comments := lintNodeInfo(node)
+	if isSuppressed(comments, r.rule.Name()) {
+		return
+	}
+	r.diagnostics = append(r.diagnostics, &Diagnostic{
+		BaseFileInfo: pos,
+		Severity:     r.severity,
+		Rule:         r.rule.Name(),
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// LintRules returns the built-in lint rules.
+func LintRules() []Rule {
+	return []Rule{
+		typePascalCase{},
+		fieldCamelCase{},
+		enumValueScreamingCase{},
+		typeDescription{},
+		rootFieldDescription{},
+		deprecatedReason{},
+		inputTypeSuffix{},
+		noNullableListItems{},
+	}
+}
+
+// Lint checks the merged schema with the built-in rules and the extra ones.
+func Lint(s *Schema, opts LintOptions) (Diagnostics, error) {
+	rules := append(LintRules(), opts.Extra...)
+
+	known := map[string]bool{}
+	for _, rule := range rules {
+		known[rule.Name()] = true
+	}
+	for name, severity := range opts.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf(`unknown lint rule "%s"`, name)
+		}
+		if _, _, err := parseRuleSeverity(severity); err != nil {
+			return nil, fmt.Errorf(`rule "%s": %s`, name, err)
+		}
+	}
+
+	ds := Diagnostics{}
+	for _, rule := range rules {
+		severity, enabled, _ := parseRuleSeverity(opts.Rules[rule.Name()])
+		if !enabled {
+			continue
+		}
+		r := LintReporter{rule: rule, severity: severity}
+		rule.Check(s, &r)
+		ds = append(ds, r.diagnostics...)
+	}
+
+	ds.sort()
+	return ds, nil
+}
+
+// MergeAndLint merges the GraphQL files like Merge and lints the merged
+// schema. The parse and merge errors are returned instead of panicking.
+func MergeAndLint(opts LintOptions, paths ...string) (ds Diagnostics, err error) {
+	defer recoverError(&err)
+
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, nil
+	}
+	return Lint(schema, opts)
+}
+
+func parseRuleSeverity(s string) (Severity, bool, error) {
+	switch s {
+	case "", "error":
+		return SeverityError, true, nil
+	case "warning", "warn":
+		return SeverityWarning, true, nil
+	case "off":
+		return SeverityError, false, nil
+	default:
+		return SeverityError, false, fmt.Errorf(`unknown severity "%s", expected error, warning or off`, s)
+	}
+}
+
+// lintNodeInfo returns the position of the node and its descriptions and
+// comments, where the suppression comments are found.
+func lintNodeInfo(node interface{}) (BaseFileInfo, []string) {
+	val := reflect.ValueOf(node)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return BaseFileInfo{}, nil
+	}
+
+	pos := BaseFileInfo{}
+	comments := []string{}
+	if f := val.FieldByName("BaseFileInfo"); f.IsValid() {
+		pos = f.Interface().(BaseFileInfo)
+	}
+	for _, name := range []string{"Descriptions", "Comments"} {
+		if f := val.FieldByName(name); f.IsValid() {
+			if ss, ok := f.Interface().(*[]string); ok && ss != nil {
+				comments = append(comments, *ss...)
+			}
+		}
+	}
+	return pos, comments
+}
+
+func isSuppressed(comments []string, rule string) bool {
+	for _, c := range comments {
+		if !strings.HasPrefix(c, "#") {
+			continue
+		}
+		c = strings.TrimSpace(strings.TrimPrefix(c, "#"))
+		if !strings.HasPrefix(c, suppressComment) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(c, suppressComment))
+		if rest == "" {
+			return true
+		}
+		for _, r := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if r == rule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LintRuleNames returns the names of the built-in rules, sorted.
+func LintRuleNames() []string {
+	names := []string{}
+	for _, r := range LintRules() {
+		names = append(names, r.Name())
+	}
+	sort.Strings(names)
+	return names
+}
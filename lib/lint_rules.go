@@ -0,0 +1,244 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	pascalCase    = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	camelCase     = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	screamingCase = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// hasDescription reports whether one of the descriptions is a string, as
+// opposed to a # comment.
+func hasDescription(descriptions *[]string) bool {
+	if descriptions == nil {
+		return false
+	}
+	for _, d := range *descriptions {
+		if strings.HasPrefix(d, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// typePascalCase requires the type names to be PascalCase.
+type typePascalCase struct{}
+
+func (typePascalCase) Name() string { return "type-pascal-case" }
+
+func (typePascalCase) Check(s *Schema, r *LintReporter) {
+	check := func(node interface{}, name string) {
+		if !pascalCase.MatchString(name) {
+			r.Report(node, `type "%s" should be PascalCase`, name)
+		}
+	}
+	for _, t := range s.Types {
+		check(t, t.Name)
+	}
+	for _, i := range s.Interfaces {
+		check(i, i.Name)
+	}
+	for _, u := range s.Unions {
+		check(u, u.Name)
+	}
+	for _, e := range s.Enums {
+		check(e, e.Name)
+	}
+	for _, i := range s.Inputs {
+		check(i, i.Name)
+	}
+	for _, c := range s.Scalars {
+		check(c, c.Name)
+	}
+}
+
+// fieldCamelCase requires the names of fields, input fields and arguments
+// to be camelCase.
+type fieldCamelCase struct{}
+
+func (fieldCamelCase) Name() string { return "field-camel-case" }
+
+func (fieldCamelCase) Check(s *Schema, r *LintReporter) {
+	fields := func(typ string, fs []*Field) {
+		for _, f := range fs {
+			if !camelCase.MatchString(f.Name) {
+				r.Report(f, `field "%s.%s" should be camelCase`, typ, f.Name)
+			}
+			for _, a := range f.Args {
+				if !camelCase.MatchString(a.Name) {
+					r.Report(a, `argument "%s" of "%s.%s" should be camelCase`, a.Name, typ, f.Name)
+				}
+			}
+		}
+	}
+	for _, t := range s.Types {
+		fields(t.Name, t.Fields)
+	}
+	for _, i := range s.Interfaces {
+		fields(i.Name, i.Fields)
+	}
+	for _, i := range s.Inputs {
+		fields(i.Name, i.Fields)
+	}
+}
+
+// enumValueScreamingCase requires the enum values to be SCREAMING_CASE.
+type enumValueScreamingCase struct{}
+
+func (enumValueScreamingCase) Name() string { return "enum-value-screaming-case" }
+
+func (enumValueScreamingCase) Check(s *Schema, r *LintReporter) {
+	for _, e := range s.Enums {
+		for i := range e.EnumValues {
+			ev := &e.EnumValues[i]
+			if !screamingCase.MatchString(ev.Name) {
+				r.Report(ev, `enum value "%s.%s" should be SCREAMING_CASE`, e.Name, ev.Name)
+			}
+		}
+	}
+}
+
+// typeDescription requires every type to have a description.
+type typeDescription struct{}
+
+func (typeDescription) Name() string { return "type-description" }
+
+func (typeDescription) Check(s *Schema, r *LintReporter) {
+	check := func(node interface{}, name string, descriptions *[]string) {
+		if !hasDescription(descriptions) {
+			r.Report(node, `type "%s" should have a description`, name)
+		}
+	}
+	for _, t := range s.Types {
+		check(t, t.Name, t.Descriptions)
+	}
+	for _, i := range s.Interfaces {
+		check(i, i.Name, i.Descriptions)
+	}
+	for _, u := range s.Unions {
+		check(u, u.Name, u.Descriptions)
+	}
+	for _, e := range s.Enums {
+		check(e, e.Name, e.Descriptions)
+	}
+	for _, i := range s.Inputs {
+		check(i, i.Name, i.Descriptions)
+	}
+	for _, c := range s.Scalars {
+		check(c, c.Name, c.Descriptions)
+	}
+}
+
+// rootFieldDescription requires every field of the operation root types to
+// have a description.
+type rootFieldDescription struct{}
+
+func (rootFieldDescription) Name() string { return "root-field-description" }
+
+func (rootFieldDescription) Check(s *Schema, r *LintReporter) {
+	roots := map[string]bool{}
+	for _, n := range s.rootTypeNames() {
+		roots[n] = true
+	}
+	for _, t := range s.Types {
+		if !roots[t.Name] {
+			continue
+		}
+		for _, f := range t.Fields {
+			if !hasDescription(f.Descriptions) {
+				r.Report(f, `root field "%s.%s" should have a description`, t.Name, f.Name)
+			}
+		}
+	}
+}
+
+// deprecatedReason requires @deprecated to have a reason.
+type deprecatedReason struct{}
+
+func (deprecatedReason) Name() string { return "deprecated-reason" }
+
+func (deprecatedReason) Check(s *Schema, r *LintReporter) {
+	check := func(node interface{}, what string, ds []*Directive) {
+		for _, d := range ds {
+			if d.Name != "deprecated" {
+				continue
+			}
+			for _, a := range d.DirectiveArgs {
+				if a.Name == "reason" && len(a.Value) > 0 && strings.Trim(a.Value[0], `" `) != "" {
+					return
+				}
+			}
+			r.Report(node, `@deprecated on %s should have a reason`, what)
+		}
+	}
+	fields := func(typ string, fs []*Field) {
+		for _, f := range fs {
+			check(f, `"`+typ+"."+f.Name+`"`, f.Directives)
+			for _, a := range f.Args {
+				check(a, `argument "`+a.Name+`" of "`+typ+"."+f.Name+`"`, a.Directives)
+			}
+		}
+	}
+	for _, t := range s.Types {
+		check(t, `"`+t.Name+`"`, t.Directives)
+		fields(t.Name, t.Fields)
+	}
+	for _, i := range s.Interfaces {
+		fields(i.Name, i.Fields)
+	}
+	for _, i := range s.Inputs {
+		fields(i.Name, i.Fields)
+	}
+	for _, e := range s.Enums {
+		for i := range e.EnumValues {
+			ev := &e.EnumValues[i]
+			check(ev, `"`+e.Name+"."+ev.Name+`"`, ev.Directives)
+		}
+	}
+}
+
+// inputTypeSuffix requires the input object names to end with Input.
+type inputTypeSuffix struct{}
+
+func (inputTypeSuffix) Name() string { return "input-type-suffix" }
+
+func (inputTypeSuffix) Check(s *Schema, r *LintReporter) {
+	for _, i := range s.Inputs {
+		if !strings.HasSuffix(i.Name, "Input") {
+			r.Report(i, `input "%s" should end with "Input"`, i.Name)
+		}
+	}
+}
+
+// noNullableListItems forbids lists of nullable items, e.g. [User].
+type noNullableListItems struct{}
+
+func (noNullableListItems) Name() string { return "no-nullable-list-items" }
+
+func (noNullableListItems) Check(s *Schema, r *LintReporter) {
+	fields := func(typ string, fs []*Field) {
+		for _, f := range fs {
+			if f.IsList && f.Null {
+				r.Report(f, `field "%s.%s" should be a list of non-null items, e.g. [%s!]`, typ, f.Name, f.Type)
+			}
+			for _, a := range f.Args {
+				if a.IsList && a.Null {
+					r.Report(a, `argument "%s" of "%s.%s" should be a list of non-null items, e.g. [%s!]`, a.Name, typ, f.Name, a.Type)
+				}
+			}
+		}
+	}
+	for _, t := range s.Types {
+		fields(t.Name, t.Fields)
+	}
+	for _, i := range s.Interfaces {
+		fields(i.Name, i.Fields)
+	}
+	for _, i := range s.Inputs {
+		fields(i.Name, i.Fields)
+	}
+}
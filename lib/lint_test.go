@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+type noUserRule struct{}
+
+func (noUserRule) Name() string { return "no-user" }
+
+func (noUserRule) Check(s *Schema, r *LintReporter) {
+	for _, t := range s.Types {
+		if t.Name == "User" {
+			r.Report(t, "no User please")
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	var src = `
+	type Query {
+		" current user "
+		me: User
+		users(first_n: Int): [User]
+		# gqlmerge-lint-disable root-field-description
+		posts: [post!]!
+	}
+
+	" user "
+	type User {
+		id: ID!
+		full_name: String @deprecated
+		legacy_name: String # gqlmerge-lint-disable field-camel-case
+	}
+
+	type post {
+		id: ID! @deprecated(reason: "use uuid")
+	}
+
+	enum Role {
+		ADMIN
+		superUser
+	}
+
+	input PostFilter {
+		id: ID
+		# trailing comment
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "schema.graphql")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	ds, err := Lint(merged, LintOptions{
+		Rules: map[string]string{"type-description": "warning", "input-type-suffix": "off"},
+		Extra: []Rule{noUserRule{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := []string{}
+	for _, d := range ds {
+		messages = append(messages, d.String())
+	}
+	all := strings.Join(messages, "\n")
+
+	expected := []string{
+		`error: argument "first_n" of "Query.users" should be camelCase (field-camel-case)`,
+		`error: root field "Query.users" should have a description (root-field-description)`,
+		`error: field "Query.users" should be a list of non-null items, e.g. [User!] (no-nullable-list-items)`,
+		`warning: type "Query" should have a description (type-description)`,
+		`error: field "User.full_name" should be camelCase (field-camel-case)`,
+		`error: @deprecated on "User.full_name" should have a reason (deprecated-reason)`,
+		`error: type "post" should be PascalCase (type-pascal-case)`,
+		`error: enum value "Role.superUser" should be SCREAMING_CASE (enum-value-screaming-case)`,
+		`error: no User please (no-user)`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Fatalf("expected %q in\n%s", e, all)
+		}
+	}
+
+	unexpected := []string{
+		`"Query.me" should have a description`,
+		`"Query.posts" should have a description`,
+		`"User.legacy_name" should be camelCase`,
+		`"post.id" should have a reason`,
+		`input-type-suffix`,
+		`type "User" should have a description`,
+	}
+	for _, u := range unexpected {
+		if strings.Contains(all, u) {
+			t.Fatalf("unexpected %q in\n%s", u, all)
+		}
+	}
+
+	if _, err := Lint(merged, LintOptions{Rules: map[string]string{"unknown-rule": "off"}}); err == nil {
+		t.Fatal("should fail with an unknown rule")
+	}
+}
+
+func TestMergeAndLintConflict(t *testing.T) {
+	if _, err := MergeAndLint(LintOptions{}, conflictingDir(t)); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+}
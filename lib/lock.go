@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// lockfileVersion is the version of the lockfile format.
+const lockfileVersion = 1
+
+// Lockfile records a canonical snapshot of the merged schema and its hash,
+// so that a change of the sources can be checked against it.
+type Lockfile struct {
+	Version int    `json:"version"`
+	Hash    string `json:"hash"`
+	Schema  string `json:"schema"`
+}
+
+// NewLockfile returns the lockfile of the merged schema.
+func NewLockfile(s *Schema) *Lockfile {
+	sdl := Canonical(s)
+	return &Lockfile{
+		Version: lockfileVersion,
+		Hash:    schemaHash(sdl),
+		Schema:  sdl,
+	}
+}
+
+// ReadLockfile reads the lockfile and checks its integrity.
+func ReadLockfile(path string) (*Lockfile, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lockfile{}
+	if err := json.Unmarshal(bs, l); err != nil {
+		return nil, fmt.Errorf("invalid lockfile '%s': %s", path, err)
+	}
+	if l.Version != lockfileVersion {
+		return nil, fmt.Errorf("unsupported version %d of lockfile '%s'", l.Version, path)
+	}
+	if l.Hash != schemaHash(l.Schema) {
+		return nil, fmt.Errorf("the hash of lockfile '%s' does not match its schema", path)
+	}
+	return l, nil
+}
+
+// Write writes the lockfile as JSON.
+func (l *Lockfile) Write(path string) error {
+	bs, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bs, '\n'), 0644)
+}
+
+// Verify compares the merged schema with the locked one. It returns no
+// change when their hashes are the same.
+func (l *Lockfile) Verify(s *Schema) Changes {
+	if NewLockfile(s).Hash == l.Hash {
+		return nil
+	}
+
+	locked := Schema{}
+	locked.Parse(NewParser(strings.NewReader(l.Schema), "<lockfile>"))
+	return Diff(mergeSchemas([]Schema{locked}, ConflictError), s)
+}
+
+// MergeAndLock merges the GraphQL files like Merge and returns the
+// lockfile of the merged schema, or nil when no GraphQL file is found. The
+// parse and merge errors are returned instead of panicking.
+func MergeAndLock(paths ...string) (l *Lockfile, err error) {
+	defer recoverError(&err)
+
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, nil
+	}
+	return NewLockfile(schema), nil
+}
+
+// MergeAndVerify merges the GraphQL files like Merge and compares the
+// merged schema with the lockfile. The parse and merge errors are returned
+// instead of panicking.
+func MergeAndVerify(lockfile string, paths ...string) (cs Changes, err error) {
+	defer recoverError(&err)
+
+	l, err := ReadLockfile(lockfile)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, fmt.Errorf("not found any GraphQL files in %v", paths)
+	}
+	return l.Verify(schema), nil
+}
+
+func schemaHash(sdl string) string {
+	sum := sha256.Sum256([]byte(sdl))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Canonical prints the schema in a canonical form, which does not depend
+// on the order of the definitions in the sources nor on their comments:
+// the definitions, their fields, arguments and values are sorted by name.
+func Canonical(s *Schema) string {
+	c := s.clone()
+
+	sort.Slice(c.Types, func(i, j int) bool { return c.Types[i].Name < c.Types[j].Name })
+	for _, t := range c.Types {
+		t.Descriptions = stringDescriptions(t.Descriptions)
+		sort.Strings(t.ImplTypes)
+		canonicalFields(t.Fields)
+	}
+
+	sort.Slice(c.Interfaces, func(i, j int) bool { return c.Interfaces[i].Name < c.Interfaces[j].Name })
+	for _, i := range c.Interfaces {
+		i.Descriptions = stringDescriptions(i.Descriptions)
+		canonicalFields(i.Fields)
+	}
+
+	sort.Slice(c.Inputs, func(i, j int) bool { return c.Inputs[i].Name < c.Inputs[j].Name })
+	for _, i := range c.Inputs {
+		i.Descriptions = stringDescriptions(i.Descriptions)
+		canonicalFields(i.Fields)
+	}
+
+	sort.Slice(c.Unions, func(i, j int) bool { return c.Unions[i].Name < c.Unions[j].Name })
+	for _, u := range c.Unions {
+		u.Descriptions = stringDescriptions(u.Descriptions)
+		sort.Strings(u.Types)
+	}
+
+	sort.Slice(c.Enums, func(i, j int) bool { return c.Enums[i].Name < c.Enums[j].Name })
+	for _, e := range c.Enums {
+		e.Descriptions = stringDescriptions(e.Descriptions)
+		sort.Slice(e.EnumValues, func(i, j int) bool { return e.EnumValues[i].Name < e.EnumValues[j].Name })
+		for i := range e.EnumValues {
+			e.EnumValues[i].Descriptions = stringDescriptions(e.EnumValues[i].Descriptions)
+			e.EnumValues[i].Comments = nil
+		}
+	}
+
+	sort.Slice(c.Scalars, func(i, j int) bool { return c.Scalars[i].Name < c.Scalars[j].Name })
+	for _, sc := range c.Scalars {
+		sc.Descriptions = stringDescriptions(sc.Descriptions)
+		sc.Comments = nil
+	}
+
+	sort.Slice(c.DirectiveDefinitions, func(i, j int) bool {
+		return c.DirectiveDefinitions[i].Name < c.DirectiveDefinitions[j].Name
+	})
+	for _, d := range c.DirectiveDefinitions {
+		d.Descriptions = stringDescriptions(d.Descriptions)
+		sort.Strings(d.Locations)
+		canonicalArgs(d.Args)
+	}
+
+	for _, sd := range c.SchemaDefinitions {
+		sd.Descriptions = stringDescriptions(sd.Descriptions)
+	}
+
+	ms := MergedSchema{Indent: "  "}
+	return ms.WriteSchema(c)
+}
+
+func canonicalFields(fs []*Field) {
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	for _, f := range fs {
+		f.Descriptions = stringDescriptions(f.Descriptions)
+		f.Comments = nil
+		canonicalArgs(f.Args)
+	}
+}
+
+func canonicalArgs(as []*Arg) {
+	sort.Slice(as, func(i, j int) bool { return as[i].Name < as[j].Name })
+	for _, a := range as {
+		a.Descriptions = stringDescriptions(a.Descriptions)
+	}
+}
+
+// stringDescriptions returns the description as it is written in the
+// merged schema, leaving the comments out.
+func stringDescriptions(descriptions *[]string) *[]string {
+	d := descriptionString(descriptions)
+	if d == "" {
+		return nil
+	}
+	return &[]string{d}
+}
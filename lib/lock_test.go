@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockfile(t *testing.T) {
+	parse := func(src string) *Schema {
+		s := Schema{}
+		p := NewParser(strings.NewReader(src), "schema.graphql")
+		s.Parse(p)
+		return mergeSchemas([]Schema{s}, ConflictError)
+	}
+
+	l := NewLockfile(parse(`
+	type Query {
+		users: [User!]! # all the users
+		user(id: ID!): User
+	}
+
+	" a user "
+	type User {
+		name: String
+		id: ID!
+	}
+`))
+
+	path := filepath.Join(t.TempDir(), "gqlmerge.lock")
+	if err := l.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	l, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the order of the definitions and the comments do not matter
+	if cs := l.Verify(parse(`
+	" a user "
+	type User {
+		id: ID!
+		name: String
+	}
+
+	type Query {
+		user(id: ID!): User
+		users: [User!]!
+	}
+`)); len(cs) != 0 {
+		t.Fatalf("expected no changes, got %v", cs)
+	}
+
+	cs := l.Verify(parse(`
+	type Query {
+		user(id: ID!): User
+	}
+
+	" a user "
+	type User {
+		id: ID!
+		name: String
+		email: String
+	}
+`))
+	if !cs.HasBreaking() {
+		t.Fatalf("expected breaking changes, got %v", cs)
+	}
+	if len(cs) != 2 || cs[0].Kind != "field-removed" || cs[1].Kind != "field-added" {
+		t.Fatalf("unexpected changes %v", cs)
+	}
+}
+
+func TestMergeAndLockConflict(t *testing.T) {
+	dir := conflictingDir(t)
+	if _, err := MergeAndLock(dir); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+
+	lockfile := filepath.Join(t.TempDir(), "gqlmerge.lock")
+	if err := (&Lockfile{Version: lockfileVersion, Hash: schemaHash("")}).Write(lockfile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeAndVerify(lockfile, dir); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+}
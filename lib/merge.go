@@ -1,8 +1,8 @@
 package lib
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -13,10 +13,69 @@ import (
 // - indent string : the padding to generate schema eg. "\t" or " "
 // - paths : A relative path to find *.graphql or *.gql files recursively
 func Merge(indent string, paths ...string) *string {
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil
+	}
+
+	reportUndefinedTypes(schema)
+
+	ms := MergedSchema{Indent: indent}
+	ss := ms.WriteSchema(schema)
+	return &ss
+}
+
+// MergeSchema merges the GraphQL files found in the paths and returns the
+// merged schema, e.g. to be inspected or changed before being printed with
+// Print, along with the references to undefined types. The parse and merge
+// errors are returned instead of panicking. The schema is nil when no
+// GraphQL file is found.
+func MergeSchema(paths ...string) (*Schema, Diagnostics, error) {
+	return MergeSchemaWith(MergeOptions{}, paths...)
+}
+
+// MergeOptions tells how the GraphQL files are selected and merged.
+type MergeOptions struct {
+	// Filter selects the files found in the paths, or every one when nil.
+	Filter *Filter
+	// OnConflict tells what to do with the definitions which cannot be
+	// merged.
+	OnConflict ConflictPolicy
+}
+
+// MergeSchemaWith merges the GraphQL files found in the paths like
+// MergeSchema, with the options.
+func MergeSchemaWith(opts MergeOptions, paths ...string) (schema *Schema, ds Diagnostics, err error) {
+	defer recoverError(&err)
+
+	schema = mergePaths(opts, paths...)
+	if schema == nil {
+		return nil, nil, nil
+	}
+	return schema, UndefinedTypes(schema), nil
+}
+
+// MergeTo merges the GraphQL files like Merge and writes the schema into w
+// instead of returning it. It reports whether any GraphQL file is found.
+func MergeTo(w io.Writer, indent string, paths ...string) (bool, error) {
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return false, nil
+	}
+
+	reportUndefinedTypes(schema)
+
+	ms := MergedSchema{Indent: indent}
+	return true, ms.Write(w, schema)
+}
+
+// mergePaths parses the GraphQL files found in the paths and merges them.
+// It returns nil when no GraphQL file is found.
+func mergePaths(opts MergeOptions, paths ...string) *Schema {
 	schemas := make([]Schema, 0, len(paths))
 
 	for _, path := range paths {
-		if sc := parseSchema(path); sc != nil {
+		if sc := parseSchema(opts.Filter, path); sc != nil {
 			schemas = append(schemas, *sc)
 		}
 	}
@@ -25,63 +84,136 @@ func Merge(indent string, paths ...string) *string {
 		return nil
 	}
 
-	schema := mergeSchemas(schemas)
-	ms := MergedSchema{Indent: indent}
-	ss := ms.WriteSchema(schema)
-	return &ss
+	return mergeSchemas(schemas, opts.OnConflict)
+}
+
+// reportUndefinedTypes prints the references to undefined types, which
+// are written as is in the generated schema.
+func reportUndefinedTypes(s *Schema) {
+	for _, d := range UndefinedTypes(s) {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", d)
+	}
 }
 
-func parseSchema(path string) *Schema {
+// Stdin is the path to read the GraphQL schema from the standard input.
+const Stdin = "-"
+
+// stdinName is the file name of the standard input in the positions.
+const stdinName = "<stdin>"
+
+// parseSchema parses the GraphQL files found in the path, selected by the
+// filter. It returns nil when no GraphQL file is found.
+func parseSchema(filter *Filter, path string) *Schema {
+	if path == Stdin {
+		sc := &Schema{}
+		sc.Sources = []*Source{{Name: stdinName, Origin: OriginStdin, Reader: os.Stdin}}
+		sc.parseSources()
+		fmt.Fprintf(os.Stderr, "🎉 [%s] GraphQL schema read\n", stdinName)
+		return sc
+	}
+
 	abs, err := filepath.Abs(path)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(0)
 	}
 
 	sc := &Schema{}
 	// at this moment, path should be an absolute path
-	sc.ReadSchema(abs)
+	sc.readSchema(filter, abs)
 
-	if len(sc.Files) == 0 {
+	if len(sc.Sources) == 0 {
 		return nil
 	}
 
-	for _, file := range sc.Files {
-		p := NewParser(bufio.NewReader(file), file.Name())
-		sc.Parse(p)
+	sc.parseSources()
+	return sc
+}
+
+// ConflictPolicy tells what to do with two definitions of the same name
+// which cannot be merged.
+type ConflictPolicy int
+
+const (
+	// ConflictError stops the merge with an error.
+	ConflictError ConflictPolicy = iota
+	// ConflictKeepFirst keeps the definition found first and reports the
+	// other one.
+	ConflictKeepFirst
+)
+
+// conflictf reports two definitions which cannot be merged.
+func (s *Schema) conflictf(format string, args ...interface{}) {
+	if s.onConflict == ConflictKeepFirst {
+		fmt.Fprintf(os.Stderr, "⚠️  %s, keeping the first one\n", fmt.Sprintf(format, args...))
+		return
 	}
+	errorf(format, args...)
+}
 
-	return sc
+// appendDefinitions appends the definitions of o to the schema, as they
+// are before merging.
+func (s *Schema) appendDefinitions(o *Schema) {
+	s.SchemaDefinitions = append(s.SchemaDefinitions, o.SchemaDefinitions...)
+	s.DirectiveDefinitions = append(s.DirectiveDefinitions, o.DirectiveDefinitions...)
+	s.Types = append(s.Types, o.Types...)
+	s.Scalars = append(s.Scalars, o.Scalars...)
+	s.Enums = append(s.Enums, o.Enums...)
+	s.Interfaces = append(s.Interfaces, o.Interfaces...)
+	s.Unions = append(s.Unions, o.Unions...)
+	s.Inputs = append(s.Inputs, o.Inputs...)
 }
 
-func mergeSchemas(schemas []Schema) *Schema {
-	schema := Schema{}
-
-	for _, s := range schemas {
-		schema.Files = append(schema.Files, s.Files...)
-		schema.SchemaDefinitions = append(schema.SchemaDefinitions, s.SchemaDefinitions...)
-		schema.DirectiveDefinitions = append(schema.DirectiveDefinitions, s.DirectiveDefinitions...)
-		schema.Types = append(schema.Types, s.Types...)
-		schema.Scalars = append(schema.Scalars, s.Scalars...)
-		schema.Enums = append(schema.Enums, s.Enums...)
-		schema.Interfaces = append(schema.Interfaces, s.Interfaces...)
-		schema.Unions = append(schema.Unions, s.Unions...)
-		schema.Inputs = append(schema.Inputs, s.Inputs...)
+func mergeSchemas(schemas []Schema, policy ConflictPolicy) *Schema {
+	schema := Schema{onConflict: policy}
+
+	for i := range schemas {
+		schema.Sources = append(schema.Sources, schemas[i].Sources...)
+		schema.appendDefinitions(&schemas[i])
 	}
 
 	wg := sync.WaitGroup{}
-	wg.Add(8)
-
-	go schema.mergeSchemaDefinition(&wg)
-	go schema.UniqueDirectiveDefinition(&wg)
-	go schema.MergeTypeName(&wg)
-	go schema.UniqueScalar(&wg)
-	go schema.UniqueEnum(&wg)
-	go schema.UniqueInterface(&wg)
-	go schema.UniqueUnion(&wg)
-	go schema.UniqueInput(&wg)
+	passes := []func(*sync.WaitGroup){
+		schema.mergeSchemaDefinition,
+		schema.UniqueDirectiveDefinition,
+		schema.MergeTypeName,
+		schema.UniqueScalar,
+		schema.UniqueEnum,
+		schema.UniqueInterface,
+		schema.UniqueUnion,
+		schema.UniqueInput,
+	}
+	wg.Add(len(passes))
+
+	// a pass panics on a conflict, which is raised again in the caller's
+	// goroutine so that it can be recovered
+	var mu sync.Mutex
+	var failure interface{}
+	done := sync.WaitGroup{}
+	done.Add(len(passes))
+	for _, pass := range passes {
+		go func(pass func(*sync.WaitGroup)) {
+			// the pass is done with wg before the panic is recovered
+			defer done.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					mu.Lock()
+					if failure == nil {
+						failure = r
+					}
+					mu.Unlock()
+				}
+			}()
+			pass(&wg)
+		}(pass)
+	}
 
 	wg.Wait()
+	done.Wait()
+
+	if failure != nil {
+		panic(failure)
+	}
 
 	return &schema
 }
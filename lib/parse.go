@@ -3,6 +3,7 @@ package lib
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 type (
@@ -14,6 +15,17 @@ func errorf(format string, args ...interface{}) {
 	panic(Error(fmt.Sprintf(format, args...)))
 }
 
+// recoverError turns the panic of a parse or merge error into err.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		e, ok := r.(Error)
+		if !ok {
+			panic(r)
+		}
+		*err = fmt.Errorf("%s", string(e))
+	}
+}
+
 type Parser struct {
 	lex *lexer
 	buf []*token
@@ -40,9 +52,12 @@ func (p *Parser) parseArgs() []*Arg {
 		p.lex.consumeToken(tokLParen)
 		for p.lex.peek() != ')' {
 			arg := Arg{}
+			arg.Filename = p.lex.filename
+			arg.Line = p.lex.line
+			arg.Column = p.lex.col
 			name, comments := p.lex.consumeIdent(tokInput, tokType)
 			arg.Name = name.String()
-			arg.Descriptions = comments
+			arg.Descriptions = p.bufStringWith(comments)
 			p.lex.consumeToken(tokColon)
 
 			if p.lex.peek() == '[' {
@@ -67,20 +82,8 @@ func (p *Parser) parseArgs() []*Arg {
 
 				if p.lex.peek() == '=' {
 					p.lex.consumeToken(tokEqual)
-					defaultValues := []string{}
-					if p.lex.peek() == '[' {
-						p.lex.consumeIdent(tokLBracket)
-						for p.lex.peek() != ']' {
-							tex, _ := p.lex.consumeIdentInclString(tokNumber)
-							te := tex.String()
-							defaultValues = append(defaultValues, te)
-							if p.lex.peek() == ',' {
-								p.lex.consumeToken(tokComma)
-							}
-						}
-						p.lex.consumeIdent(tokRBracket)
-						arg.DefaultValues = &defaultValues
-					}
+					defaultValues := []string{p.parseValueLiteral()}
+					arg.DefaultValues = &defaultValues
 				}
 			} else {
 				typ, _ := p.lex.consumeIdent()
@@ -95,10 +98,7 @@ func (p *Parser) parseArgs() []*Arg {
 
 				if p.lex.peek() == '=' {
 					p.lex.consumeToken(tokEqual)
-					tex, _ := p.lex.consumeIdentInclString(tokNumber)
-					te := tex.String()
-					defaultValues := []string{}
-					defaultValues = append(defaultValues, te)
+					defaultValues := []string{p.parseValueLiteral()}
 					arg.DefaultValues = &defaultValues
 				}
 			}
@@ -121,6 +121,9 @@ func (p *Parser) parseDirectives() []*Directive {
 	for p.lex.peek() == '@' {
 		p.lex.consumeToken(tokAt)
 		d := Directive{}
+		d.Filename = p.lex.filename
+		d.Line = p.lex.line
+		d.Column = p.lex.col
 		name, comments := p.lex.consumeIdent()
 		d.Name = name.String()
 		d.Descriptions = comments
@@ -139,6 +142,9 @@ func (p *Parser) parseDirectives() []*Directive {
 					p.lex.consumeToken(tokLBracket)
 					da.Value = p.parseList()
 					p.lex.consumeToken(tokRBracket)
+				} else if p.lex.peek() == '{' {
+					da.IsList = false
+					da.Value = append(da.Value, p.parseValueLiteral())
 				} else {
 					da.IsList = false
 					tok := p.lex.next()
@@ -162,6 +168,41 @@ func (p *Parser) parseDirectives() []*Directive {
 	return ds
 }
 
+// parseValueLiteral parses a value and returns its text, e.g. "text", 20,
+// ADMIN, [1, 2] or {name: "text", tags: ["a"]}
+func (p *Parser) parseValueLiteral() string {
+	switch p.lex.peek() {
+	case '[':
+		p.lex.consumeToken(tokLBracket)
+		vs := []string{}
+		for p.lex.peek() != ']' {
+			vs = append(vs, p.parseValueLiteral())
+			if p.lex.peek() == ',' {
+				p.lex.consumeToken(tokComma)
+			}
+		}
+		p.lex.consumeToken(tokRBracket)
+		return "[" + strings.Join(vs, ", ") + "]"
+
+	case '{':
+		p.lex.consumeToken(tokLBrace)
+		fs := []string{}
+		for p.lex.peek() != '}' {
+			name, _ := p.lex.consumeIdent(tokInput, tokType)
+			p.lex.consumeToken(tokColon)
+			fs = append(fs, name.String()+": "+p.parseValueLiteral())
+			if p.lex.peek() == ',' {
+				p.lex.consumeToken(tokComma)
+			}
+		}
+		p.lex.consumeToken(tokRBrace)
+		return "{" + strings.Join(fs, ", ") + "}"
+	}
+
+	tex, _ := p.lex.consumeIdentInclString(tokNumber)
+	return tex.String()
+}
+
 func (p *Parser) parseList() []string {
 	ss := []string{}
 	for p.lex.peek() != ']' {
@@ -177,12 +218,33 @@ func (p *Parser) parseList() []string {
 	return ss
 }
 
+// parseSingleLineComment returns the comment written on the same line as
+// the last token. A comment on the next lines belongs to what follows, and
+// is buffered for it, unless nothing follows in the block.
 func (p *Parser) parseSingleLineComment() *string {
 	if p.lex.peek() == '#' {
-		tok := p.lex.next().String()
+		sameLine := p.lex.line == p.lex.tokLine
+		tok := p.lex.next()
 		p.lex.skipSpace()
-		return &tok
+		if !sameLine && p.lex.peek() != '}' && p.lex.peek() != EofRune {
+			p.buf = append(p.buf, tok)
+			return nil
+		}
+		s := tok.String()
+		return &s
 	}
 	p.lex.skipSpace()
 	return nil
 }
+
+// bufStringWith returns the buffered comments followed by the comments.
+func (p *Parser) bufStringWith(comments *[]string) *[]string {
+	if len(p.buf) == 0 {
+		return comments
+	}
+	ss := *p.bufString()
+	if comments != nil {
+		ss = append(ss, *comments...)
+	}
+	return &ss
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeSchemaAndPrint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("query.graphql", "type Query {\n  user: User\n  org: Org\n}")
+	write("user.graphql", "type User { id: ID! }")
+
+	s, ds, err := MergeSchema(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 || !strings.Contains(ds[0].Message, `"Org"`) {
+		t.Errorf("expected the undefined Org, got %v", ds)
+	}
+
+	// the merged schema can be changed before being printed
+	for _, ty := range s.Types {
+		if ty.Name == "User" {
+			ty.Fields = append(ty.Fields, &Field{Name: "name", Type: "String", Null: true})
+		}
+	}
+
+	expected := "type Query {\n  user: User\n  org: Org\n}\n\ntype User {\n  id: ID!\n  name: String\n}"
+	if out := printSchema(t, s, PrintOptions{Indent: "  "}); strings.TrimSpace(out) != expected {
+		t.Errorf("unexpected schema\n%s", out)
+	}
+
+	var sb strings.Builder
+	if err := Fprint(&sb, s, PrintOptions{Indent: "  "}); err != nil || strings.TrimSpace(sb.String()) != expected {
+		t.Errorf("unexpected schema %v\n%s", err, sb.String())
+	}
+
+	if out := printSchema(t, &Schema{}, PrintOptions{}); strings.TrimSpace(out) != "" {
+		t.Errorf("expected an empty schema, got %q", out)
+	}
+
+	write("conflict.graphql", "type User { id: String }")
+	if _, _, err := MergeSchema(dir); err == nil || !strings.Contains(err.Error(), "Duplicated Types") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+}
+
+// printSchema prints the schema like Print, failing the test on an error.
+func printSchema(t *testing.T, s *Schema, opts PrintOptions) string {
+	t.Helper()
+	out, err := Print(s, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,366 @@
+package lib
+
+// rootTypeNames returns the names of the operation root types. Without a
+// schema definition the default names Query, Mutation and Subscription are
+// used.
+func (s *Schema) rootTypeNames() []string {
+	names := []string{}
+	if len(s.SchemaDefinitions) > 0 {
+		sd := s.SchemaDefinitions[0]
+		for _, n := range []*string{sd.Query, sd.Mutation, sd.Subscription} {
+			if n != nil {
+				names = append(names, *n)
+			}
+		}
+	}
+	if len(names) > 0 {
+		return names
+	}
+	for _, t := range s.Types {
+		if t.Name == "Query" || t.Name == "Mutation" || t.Name == "Subscription" {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// definedTypeNames returns the names of all the named types in the schema.
+func (s *Schema) definedTypeNames() map[string]bool {
+	names := map[string]bool{}
+	for _, t := range s.Types {
+		names[t.Name] = true
+	}
+	for _, i := range s.Interfaces {
+		names[i.Name] = true
+	}
+	for _, i := range s.Inputs {
+		names[i.Name] = true
+	}
+	for _, e := range s.Enums {
+		names[e.Name] = true
+	}
+	for _, u := range s.Unions {
+		names[u.Name] = true
+	}
+	for _, c := range s.Scalars {
+		names[c.Name] = true
+	}
+	return names
+}
+
+// emptyTypeNames returns the names of the types without any field, value
+// or member.
+func (s *Schema) emptyTypeNames() map[string]bool {
+	names := map[string]bool{}
+	for _, t := range s.Types {
+		if len(t.Fields) == 0 {
+			names[t.Name] = true
+		}
+	}
+	for _, i := range s.Interfaces {
+		if len(i.Fields) == 0 {
+			names[i.Name] = true
+		}
+	}
+	for _, i := range s.Inputs {
+		if len(i.Fields) == 0 {
+			names[i.Name] = true
+		}
+	}
+	for _, e := range s.Enums {
+		if len(e.EnumValues) == 0 {
+			names[e.Name] = true
+		}
+	}
+	for _, u := range s.Unions {
+		if len(u.Types) == 0 {
+			names[u.Name] = true
+		}
+	}
+	return names
+}
+
+// reachableTypeNames returns the names of the types which can be reached
+// from the operation root types and the directive definitions. The object
+// types implementing a reachable interface are reachable as well.
+func (s *Schema) reachableTypeNames() map[string]bool {
+	types := map[string]*Type{}
+	for _, t := range s.Types {
+		types[t.Name] = t
+	}
+	interfaces := map[string]*Interface{}
+	for _, i := range s.Interfaces {
+		interfaces[i.Name] = i
+	}
+	inputs := map[string]*Input{}
+	for _, i := range s.Inputs {
+		inputs[i.Name] = i
+	}
+	unions := map[string]*Union{}
+	for _, u := range s.Unions {
+		unions[u.Name] = u
+	}
+
+	seen := map[string]bool{}
+	queue := s.rootTypeNames()
+	for _, d := range s.DirectiveDefinitions {
+		for _, a := range d.Args {
+			queue = append(queue, a.Type)
+		}
+	}
+
+	visitFields := func(fs []*Field) {
+		for _, f := range fs {
+			queue = append(queue, f.Type)
+			for _, a := range f.Args {
+				queue = append(queue, a.Type)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if t, ok := types[name]; ok {
+			queue = append(queue, t.ImplTypes...)
+			visitFields(t.Fields)
+		}
+		if i, ok := interfaces[name]; ok {
+			visitFields(i.Fields)
+			for _, t := range s.Types {
+				for _, it := range t.ImplTypes {
+					if it == name {
+						queue = append(queue, t.Name)
+					}
+				}
+			}
+		}
+		if i, ok := inputs[name]; ok {
+			visitFields(i.Fields)
+		}
+		if u, ok := unions[name]; ok {
+			queue = append(queue, u.Types...)
+		}
+	}
+
+	return seen
+}
+
+// prune removes whatever was left dangling after some definitions were
+// removed from the schema: fields, arguments and input fields of a removed
+// type, union members and implemented interfaces which no longer exist,
+// types left without any field, value or member, and types which became
+// unreachable. defined, reachable and empty are the type names of the
+// schema before anything was removed, so that types which were never
+// defined, never reachable or always empty are left alone.
+func (s *Schema) prune(defined, reachable, empty map[string]bool) {
+	for {
+		current := s.definedTypeNames()
+		removed := map[string]bool{}
+		for name := range defined {
+			if !current[name] {
+				removed[name] = true
+			}
+		}
+
+		changed := false
+
+		filterArgs := func(as []*Arg) []*Arg {
+			kept := as[:0]
+			for _, a := range as {
+				if removed[a.Type] {
+					changed = true
+					continue
+				}
+				kept = append(kept, a)
+			}
+			return kept
+		}
+		filterFields := func(fs []*Field) []*Field {
+			kept := fs[:0]
+			for _, f := range fs {
+				if removed[f.Type] {
+					changed = true
+					continue
+				}
+				f.Args = filterArgs(f.Args)
+				kept = append(kept, f)
+			}
+			return kept
+		}
+
+		for _, d := range s.DirectiveDefinitions {
+			d.Args = filterArgs(d.Args)
+		}
+
+		types := s.Types[:0]
+		for _, t := range s.Types {
+			t.Fields = filterFields(t.Fields)
+			impls := t.ImplTypes[:0]
+			for _, it := range t.ImplTypes {
+				if removed[it] {
+					changed = true
+					continue
+				}
+				impls = append(impls, it)
+			}
+			t.ImplTypes = impls
+			t.Impl = len(t.ImplTypes) > 0
+			if len(t.Fields) == 0 && !empty[t.Name] {
+				changed = true
+				continue
+			}
+			types = append(types, t)
+		}
+		s.Types = types
+
+		interfaces := s.Interfaces[:0]
+		for _, i := range s.Interfaces {
+			i.Fields = filterFields(i.Fields)
+			if len(i.Fields) == 0 && !empty[i.Name] {
+				changed = true
+				continue
+			}
+			interfaces = append(interfaces, i)
+		}
+		s.Interfaces = interfaces
+
+		inputs := s.Inputs[:0]
+		for _, i := range s.Inputs {
+			i.Fields = filterFields(i.Fields)
+			if len(i.Fields) == 0 && !empty[i.Name] {
+				changed = true
+				continue
+			}
+			inputs = append(inputs, i)
+		}
+		s.Inputs = inputs
+
+		enums := s.Enums[:0]
+		for _, e := range s.Enums {
+			if len(e.EnumValues) == 0 && !empty[e.Name] {
+				changed = true
+				continue
+			}
+			enums = append(enums, e)
+		}
+		s.Enums = enums
+
+		unions := s.Unions[:0]
+		for _, u := range s.Unions {
+			members := u.Types[:0]
+			for _, m := range u.Types {
+				if removed[m] {
+					changed = true
+					continue
+				}
+				members = append(members, m)
+			}
+			u.Types = members
+			if len(u.Types) == 0 && !empty[u.Name] {
+				changed = true
+				continue
+			}
+			unions = append(unions, u)
+		}
+		s.Unions = unions
+
+		if !changed {
+			changed = s.removeUnreachable(reachable)
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	current := s.definedTypeNames()
+	for _, sd := range s.SchemaDefinitions {
+		if sd.Query != nil && defined[*sd.Query] && !current[*sd.Query] {
+			sd.Query = nil
+		}
+		if sd.Mutation != nil && defined[*sd.Mutation] && !current[*sd.Mutation] {
+			sd.Mutation = nil
+		}
+		if sd.Subscription != nil && defined[*sd.Subscription] && !current[*sd.Subscription] {
+			sd.Subscription = nil
+		}
+	}
+}
+
+// removeUnreachable removes the types which were reachable before but no
+// longer are. It reports whether anything was removed.
+func (s *Schema) removeUnreachable(before map[string]bool) bool {
+	now := s.reachableTypeNames()
+	drop := func(name string) bool {
+		return before[name] && !now[name]
+	}
+	changed := false
+
+	types := s.Types[:0]
+	for _, t := range s.Types {
+		if drop(t.Name) {
+			changed = true
+			continue
+		}
+		types = append(types, t)
+	}
+	s.Types = types
+
+	interfaces := s.Interfaces[:0]
+	for _, i := range s.Interfaces {
+		if drop(i.Name) {
+			changed = true
+			continue
+		}
+		interfaces = append(interfaces, i)
+	}
+	s.Interfaces = interfaces
+
+	inputs := s.Inputs[:0]
+	for _, i := range s.Inputs {
+		if drop(i.Name) {
+			changed = true
+			continue
+		}
+		inputs = append(inputs, i)
+	}
+	s.Inputs = inputs
+
+	enums := s.Enums[:0]
+	for _, e := range s.Enums {
+		if drop(e.Name) {
+			changed = true
+			continue
+		}
+		enums = append(enums, e)
+	}
+	s.Enums = enums
+
+	unions := s.Unions[:0]
+	for _, u := range s.Unions {
+		if drop(u.Name) {
+			changed = true
+			continue
+		}
+		unions = append(unions, u)
+	}
+	s.Unions = unions
+
+	scalars := s.Scalars[:0]
+	for _, c := range s.Scalars {
+		if drop(c.Name) {
+			changed = true
+			continue
+		}
+		scalars = append(scalars, c)
+	}
+	s.Scalars = scalars
+
+	return changed
+}
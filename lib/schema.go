@@ -3,7 +3,6 @@ package lib
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"reflect"
 	"sort"
 	"sync"
@@ -11,42 +10,24 @@ import (
 
 // GetSchema is to parse ./schema/**/*.graphql
 func (sc *Schema) ReadSchema(path string) {
+	sc.readSchema(nil, path)
+}
+
+// readSchema finds the GraphQL files in the path which are selected by the
+// filter.
+func (sc *Schema) readSchema(filter *Filter, path string) {
 	// FIX: is there any way to use a relative path?
 	// currently, it works only with absolute path
 	// in case of using a relative path such as '../schema', it spits out an error
 	// the error says invalid memory or nil pointer deference.
-	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if p == "" {
-			return nil
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		ext := filepath.Ext(p)
-		if ext != ".graphql" && ext != ".gql" {
-			return nil
-		}
-
-		file, err := os.Open(p)
-		if err != nil {
-			fmt.Printf("[Error] There is an error to open %s", p)
-			return err
-		}
-
-		// TODO: split and get a only filename and print it to user
-		// needs to handle in case of OS (windows / unix compatibles)
-		sc.Files = append(sc.Files, file)
-
+	err := walkSchemaFiles(filter, path, func(p string, info os.FileInfo) error {
+		// the file is opened only while it is parsed, so that a large
+		// tree does not run out of file descriptors
+		sc.Sources = append(sc.Sources, fileSource(p))
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		errorf("%s", err)
 	}
 
 	rel, err := GetRelPath(path)
@@ -55,8 +36,8 @@ func (sc *Schema) ReadSchema(path string) {
 		path = *rel
 	}
 
-	if len(sc.Files) > 0 {
-		fmt.Printf("🎉 [%s] Total %d GraphQL files found!\n", path, len(sc.Files))
+	if len(sc.Sources) > 0 {
+		fmt.Fprintf(os.Stderr, "🎉 [%s] Total %d GraphQL files found!\n", path, len(sc.Sources))
 	}
 }
 
@@ -119,8 +100,9 @@ func (s *Schema) Parse(p *Parser) {
 			t := p.lex.next()
 			if t.typ == tokRepeatable {
 				d.Repeatable = true
-			} else if t.typ == tokOn {
-				d.Repeatable = false
+				t = p.lex.next()
+			}
+			if t.typ == tokOn {
 				ls := []string{}
 				for p.lex.peek() != EofRune {
 					l, _ := p.lex.consumeIdent()
@@ -169,9 +151,12 @@ func (s *Schema) Parse(p *Parser) {
 			p.lex.consumeToken(tokLBrace)
 			for p.lex.peek() != '}' {
 				ev := EnumValue{}
+				ev.Filename = p.lex.filename
+				ev.Line = p.lex.line
+				ev.Column = p.lex.col
 				name, comments := p.lex.consumeIdent()
 				ev.Name = name.String()
-				ev.Descriptions = comments
+				ev.Descriptions = p.bufStringWith(comments)
 				ev.Directives = p.parseDirectives()
 				sc := p.parseSingleLineComment()
 				if ev.Comments != nil && sc != nil {
@@ -204,7 +189,7 @@ func (s *Schema) Parse(p *Parser) {
 				fd.Column = p.lex.col
 				name, comments := p.lex.consumeIdent(tokInput, tokType)
 				fd.Name = name.String()
-				fd.Descriptions = comments
+				fd.Descriptions = p.bufStringWith(comments)
 
 				fd.Args = p.parseArgs()
 
@@ -299,7 +284,7 @@ func (s *Schema) Parse(p *Parser) {
 				fd.Column = p.lex.col
 				name, comments := p.lex.consumeIdent(tokInput, tokType)
 				fd.Name = name.String()
-				fd.Descriptions = comments
+				fd.Descriptions = p.bufStringWith(comments)
 				p.lex.consumeToken(tokColon)
 
 				if p.lex.peek() == '[' {
@@ -322,20 +307,8 @@ func (s *Schema) Parse(p *Parser) {
 					}
 					if p.lex.peek() == '=' {
 						p.lex.consumeToken(tokEqual)
-						defaultValues := []string{}
-						if p.lex.peek() == '[' {
-							p.lex.consumeIdent(tokLBracket)
-							for p.lex.peek() != ']' {
-								tex, _ := p.lex.consumeIdentInclString(tokNumber)
-								te := tex.String()
-								defaultValues = append(defaultValues, te)
-								if p.lex.peek() == ',' {
-									p.lex.consumeToken(tokComma)
-								}
-							}
-							p.lex.consumeIdent(tokRBracket)
-							fd.DefaultValues = &defaultValues
-						}
+						defaultValues := []string{p.parseValueLiteral()}
+						fd.DefaultValues = &defaultValues
 					}
 				} else {
 					fd.IsList = false
@@ -351,10 +324,7 @@ func (s *Schema) Parse(p *Parser) {
 
 					if p.lex.peek() == '=' {
 						p.lex.consumeToken(tokEqual)
-						tex, _ := p.lex.consumeIdentInclString(tokNumber)
-						te := tex.String()
-						defaultValues := []string{}
-						defaultValues = append(defaultValues, te)
+						defaultValues := []string{p.parseValueLiteral()}
 						fd.DefaultValues = &defaultValues
 					}
 				}
@@ -413,7 +383,7 @@ func (s *Schema) Parse(p *Parser) {
 					fd.Column = p.lex.col
 					name, comments := p.lex.consumeIdent(tokInput, tokType)
 					fd.Name = name.String()
-					fd.Descriptions = comments
+					fd.Descriptions = p.bufStringWith(comments)
 
 					fd.Args = p.parseArgs()
 
@@ -493,7 +463,7 @@ func (s *Schema) mergeSchemaDefinition(wg *sync.WaitGroup) {
 			if err != nil {
 				panic(err)
 			}
-			errorf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Query, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
+			s.conflictf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Query, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
 		}
 		if sd.Mutation == nil {
 			sd.Mutation = v.Mutation
@@ -506,7 +476,7 @@ func (s *Schema) mergeSchemaDefinition(wg *sync.WaitGroup) {
 			if err != nil {
 				panic(err)
 			}
-			errorf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Mutation, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
+			s.conflictf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Mutation, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
 		}
 		if sd.Subscription == nil {
 			sd.Subscription = v.Subscription
@@ -519,7 +489,7 @@ func (s *Schema) mergeSchemaDefinition(wg *sync.WaitGroup) {
 			if err != nil {
 				panic(err)
 			}
-			errorf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Subscription, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
+			s.conflictf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", *sd.Subscription, *rel1, sd.Line, sd.Column, *rel2, v.Line, v.Column)
 		}
 
 		sd.Descriptions = mergeStrings(sd.Descriptions, v.Descriptions)
@@ -549,7 +519,7 @@ func (s *Schema) UniqueDirectiveDefinition(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", s.DirectiveDefinitions[i].Name, *rel1, s.DirectiveDefinitions[i].Line, s.DirectiveDefinitions[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Directive Definitions: %s(%s:%v:%v) and (%s:%v:%v)", s.DirectiveDefinitions[i].Name, *rel1, s.DirectiveDefinitions[i].Line, s.DirectiveDefinitions[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -574,12 +544,12 @@ func (s *Schema) MergeTypeName(wg *sync.WaitGroup) {
 			for i := 0; i < j; i++ {
 				if s.Types[i].Name == v.Name {
 					if v.Extend {
-						s.Types[i].Fields = mergeFields(s.Types[i].Fields, v.Fields)
+						s.Types[i].Fields = s.mergeFields(s.Types[i].Fields, v.Fields)
 						s.Types[i].Directives = mergeDirectives(s.Types[i].Directives, v.Directives)
 						break
 					} else {
 						if reflect.DeepEqual(s.Types[i].ImplTypes, v.ImplTypes) && IsEqualWithoutDescriptions(s.Types[i].Directives, v.Directives) {
-							s.Types[i].Fields = mergeFields(s.Types[i].Fields, v.Fields)
+							s.Types[i].Fields = s.mergeFields(s.Types[i].Fields, v.Fields)
 							mergeDescriptionsAndComments(s.Types[i].Directives, v.Directives)
 							break
 						} else {
@@ -593,7 +563,7 @@ func (s *Schema) MergeTypeName(wg *sync.WaitGroup) {
 								panic(err)
 							}
 
-							errorf("Duplicated Types: %s(%s:%v:%v) and (%s:%v:%v)", s.Types[i].Name, *rel1, s.Types[i].Line, s.Types[i].Column, *rel2, v.Line, v.Column)
+							s.conflictf("Duplicated Types: %s(%s:%v:%v) and (%s:%v:%v)", s.Types[i].Name, *rel1, s.Types[i].Line, s.Types[i].Column, *rel2, v.Line, v.Column)
 						}
 					}
 				}
@@ -628,7 +598,7 @@ func (s *Schema) UniqueScalar(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Scalars: %s(%s:%v:%v) and (%s:%v:%v)", s.Scalars[i].Name, *rel1, s.Scalars[i].Line, s.Scalars[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Scalars: %s(%s:%v:%v) and (%s:%v:%v)", s.Scalars[i].Name, *rel1, s.Scalars[i].Line, s.Scalars[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -663,7 +633,7 @@ func (s *Schema) UniqueEnum(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Enums: %s(%s:%v:%v) and (%s:%v:%v)", s.Enums[i].Name, *rel1, s.Enums[i].Line, s.Enums[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Enums: %s(%s:%v:%v) and (%s:%v:%v)", s.Enums[i].Name, *rel1, s.Enums[i].Line, s.Enums[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -698,7 +668,7 @@ func (s *Schema) UniqueInterface(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Interfaces: %s(%s:%v:%v) and (%s:%v:%v)", s.Interfaces[i].Name, *rel1, s.Interfaces[i].Line, s.Interfaces[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Interfaces: %s(%s:%v:%v) and (%s:%v:%v)", s.Interfaces[i].Name, *rel1, s.Interfaces[i].Line, s.Interfaces[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -733,7 +703,7 @@ func (s *Schema) UniqueUnion(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Unions: %s(%s:%v:%v) and (%s:%v:%v)", s.Unions[i].Name, *rel1, s.Unions[i].Line, s.Unions[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Unions: %s(%s:%v:%v) and (%s:%v:%v)", s.Unions[i].Name, *rel1, s.Unions[i].Line, s.Unions[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -768,7 +738,7 @@ func (s *Schema) UniqueInput(wg *sync.WaitGroup) {
 							panic(err)
 						}
 
-						errorf("Duplicated Inputs: %s(%s:%v:%v) and (%s:%v:%v)", s.Inputs[i].Name, *rel1, s.Inputs[i].Line, s.Inputs[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Inputs: %s(%s:%v:%v) and (%s:%v:%v)", s.Inputs[i].Name, *rel1, s.Inputs[i].Line, s.Inputs[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
@@ -781,7 +751,7 @@ func (s *Schema) UniqueInput(wg *sync.WaitGroup) {
 	s.Inputs = s.Inputs[:j]
 }
 
-func mergeFields(a []*Field, b []*Field) []*Field {
+func (s *Schema) mergeFields(a []*Field, b []*Field) []*Field {
 	ps := make([]*Field, len(a)+len(b))
 	j := 0
 	seen := make(map[string]struct{}, len(a)+len(b))
@@ -803,7 +773,7 @@ func mergeFields(a []*Field, b []*Field) []*Field {
 							panic(err)
 						}
 
-						errorf("Duplicated Types: %s(%s:%v:%v) and (%s:%v:%v)", combined[i].Name, *rel1, combined[i].Line, combined[i].Column, *rel2, v.Line, v.Column)
+						s.conflictf("Duplicated Types: %s(%s:%v:%v) and (%s:%v:%v)", combined[i].Name, *rel1, combined[i].Line, combined[i].Column, *rel2, v.Line, v.Column)
 					}
 				}
 			}
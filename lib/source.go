@@ -0,0 +1,161 @@
+package lib
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Origin tells where a source comes from.
+type Origin int
+
+const (
+	// OriginReader is a reader given to MergeReaders or MergeStrings.
+	OriginReader Origin = iota
+	// OriginFile is a file on disk.
+	OriginFile
+	// OriginStdin is the standard input.
+	OriginStdin
+	// OriginFS is a file of an fs.FS given to MergeFS.
+	OriginFS
+)
+
+// Source is a named GraphQL schema. The name is used in the positions of
+// the errors, e.g. the path of a file.
+type Source struct {
+	Name   string
+	Origin Origin
+	// Reader is the content of a source given as is, the other sources
+	// are opened only while they are parsed.
+	Reader io.Reader
+
+	open func() (io.ReadCloser, error)
+}
+
+// fileSource returns the source of a file on disk.
+func fileSource(path string) *Source {
+	return &Source{
+		Name:   path,
+		Origin: OriginFile,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}
+}
+
+// fsSource returns the source of a file of fsys.
+func fsSource(fsys fs.FS, name string) *Source {
+	return &Source{
+		Name:   name,
+		Origin: OriginFS,
+		open: func() (io.ReadCloser, error) {
+			return fsys.Open(name)
+		},
+	}
+}
+
+// Open returns the content of the source, which is closed by the caller.
+func (src *Source) Open() (io.ReadCloser, error) {
+	if src.open != nil {
+		return src.open()
+	}
+	return io.NopCloser(src.Reader), nil
+}
+
+// parseSources parses the sources of the schema one after the other, each
+// one being closed before the next one is opened. The files on disk are
+// parsed through the cache when it is set. A source starting with a brace
+// is read as an introspection result instead of the SDL.
+func (s *Schema) parseSources() {
+	for _, src := range s.Sources {
+		if cache != nil && src.Origin == OriginFile {
+			s.appendDefinitions(cache.parse(src.Name))
+			continue
+		}
+		s.parseSource(src)
+	}
+}
+
+func (s *Schema) parseSource(src *Source) {
+	r, err := src.Open()
+	if err != nil {
+		errorf("%s: %s", src.Name, err)
+	}
+	defer r.Close()
+
+	s.appendDefinitions(parseContent(r, src.Name))
+}
+
+// MergeFS merges the GraphQL files found in the roots of fsys, e.g. an
+// embed.FS, like Merge. The roots and the names in the positions are
+// slash separated paths in fsys, "." being the whole of it. It returns nil
+// when no GraphQL file is found.
+func MergeFS(indent string, fsys fs.FS, roots ...string) (ss *string, err error) {
+	defer recoverError(&err)
+
+	schemas := make([]Schema, 0, len(roots))
+	for _, root := range roots {
+		sc := &Schema{}
+		err := walkFS(nil, fsys, root, func(name string, d fs.DirEntry) error {
+			sc.Sources = append(sc.Sources, fsSource(fsys, name))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(sc.Sources) > 0 {
+			sc.parseSources()
+			schemas = append(schemas, *sc)
+		}
+	}
+
+	return writeMerged(indent, schemas), nil
+}
+
+// MergeReaders merges the sources, in the given order, like Merge. It
+// returns nil when there is no source.
+func MergeReaders(indent string, sources ...Source) (ss *string, err error) {
+	defer recoverError(&err)
+
+	schemas := make([]Schema, 0, len(sources))
+	for i := range sources {
+		sc := &Schema{Sources: []*Source{&sources[i]}}
+		sc.parseSources()
+		schemas = append(schemas, *sc)
+	}
+
+	return writeMerged(indent, schemas), nil
+}
+
+// MergeStrings merges the schemas by name, in the order of the names, like
+// Merge.
+func MergeStrings(indent string, sources map[string]string) (*string, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	srcs := make([]Source, 0, len(names))
+	for _, name := range names {
+		srcs = append(srcs, Source{Name: name, Reader: strings.NewReader(sources[name])})
+	}
+	return MergeReaders(indent, srcs...)
+}
+
+// writeMerged merges the parsed schemas and prints the result, or returns
+// nil when there is none.
+func writeMerged(indent string, schemas []Schema) *string {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	schema := mergeSchemas(schemas, ConflictError)
+	reportUndefinedTypes(schema)
+
+	ms := MergedSchema{Indent: indent}
+	ss := ms.WriteSchema(schema)
+	return &ss
+}
@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMergeSources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema/query.graphql":             {Data: []byte("type Query { user: User }")},
+		"schema/user.graphql":              {Data: []byte("type User { id: ID! }")},
+		"schema/user_name.gql":             {Data: []byte("type User { name: String }")},
+		"schema/README.md":                 {Data: []byte("# not a schema")},
+		"schema/__fixtures__/user.graphql": {Data: []byte("type User { id: String }")},
+		"schema/" + IgnoreFile:             {Data: []byte("__fixtures__/\n")},
+		"other/conflict.graphql":           {Data: []byte("type User { id: Int }")},
+	}
+
+	expected := "type Query {\n  user: User\n}\n\ntype User {\n  id: ID!\n  name: String\n}"
+
+	ss, err := MergeFS("  ", fsys, "schema")
+	if err != nil || ss == nil {
+		t.Fatalf("expected the schema to be merged, got %v", err)
+	}
+	if strings.TrimSpace(*ss) != expected {
+		t.Errorf("unexpected schema\n%s", *ss)
+	}
+
+	if _, err := MergeFS("  ", fsys, "schema", "other"); err == nil || !strings.Contains(err.Error(), "schema/user.graphql:1:12") || !strings.Contains(err.Error(), "other/conflict.graphql:1:12") {
+		t.Errorf("expected a conflict with the names in fsys, got %v", err)
+	}
+
+	if ss, err := MergeFS("  ", fsys, "other/none"); err == nil || ss != nil {
+		t.Errorf("expected a missing root to fail, got %v", err)
+	}
+
+	ss, err = MergeStrings("  ", map[string]string{
+		"b.graphql": "type User { name: String }",
+		"a.graphql": "type Query { user: User }\ntype User { id: ID! }",
+	})
+	if err != nil || ss == nil {
+		t.Fatalf("expected the schema to be merged, got %v", err)
+	}
+	if strings.TrimSpace(*ss) != expected {
+		t.Errorf("unexpected schema\n%s", *ss)
+	}
+
+	_, err = MergeReaders("  ", Source{Name: "broken.graphql", Reader: strings.NewReader("type User {")})
+	if err == nil || !strings.HasPrefix(err.Error(), "broken.graphql:") {
+		t.Errorf("expected a parse error in broken.graphql, got %v", err)
+	}
+}
+
+func TestRemovedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user.graphql")
+
+	// the file is removed between the walk and the parse
+	if _, err := parseFile(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected an error for the removed file, got %v", err)
+	}
+
+	c, err := NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseCache(c)
+	defer UseCache(nil)
+
+	if _, err := parseFile(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected an error for the removed file through the cache, got %v", err)
+	}
+
+	if _, _, err := MergeSchemaWith(MergeOptions{}, filepath.Join(dir, "none")); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+}
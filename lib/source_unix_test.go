@@ -0,0 +1,43 @@
+//go:build unix
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestMergeMoreFilesThanTheLimit(t *testing.T) {
+	dir := t.TempDir()
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Skip(err)
+	}
+	lowered := limit
+	lowered.Cur = 64
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &lowered); err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit)
+
+	n := int(lowered.Cur) * 2
+	for i := 0; i < n; i++ {
+		src := fmt.Sprintf("type T%03d { id: ID! }", i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("t%03d.graphql", i)), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ss := Merge("  ", dir)
+	if ss == nil {
+		t.Fatal("expected the files to be merged")
+	}
+	if c := strings.Count(*ss, "type T"); c != n {
+		t.Errorf("expected %d types, got %d", n, c)
+	}
+}
@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestMergeStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	go func() {
+		w.WriteString("type Query { user: User }\ntype User { id: ID! }\ntype User { name: String }\n")
+		w.Close()
+	}()
+
+	var sb strings.Builder
+	found, err := MergeTo(&sb, "  ", Stdin)
+	if err != nil || !found {
+		t.Fatalf("expected the schema to be merged, got %v %v", found, err)
+	}
+
+	expected := "type Query {\n  user: User\n}\n\ntype User {\n  id: ID!\n  name: String\n}"
+	if strings.TrimSpace(sb.String()) != expected {
+		t.Errorf("unexpected schema\n%s", sb.String())
+	}
+
+	s := &Schema{}
+	s.Parse(NewParser(strings.NewReader("type Query { id: ID }"), stdinName))
+	ms := MergedSchema{Indent: "  "}
+	if err := ms.Write(failingWriter{}, mergeSchemas([]Schema{*s}, ConflictError)); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the error of the writer, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// suggestNames returns the candidates which are close enough to name to be
+// a typo of it, the closest first. Case differences count as a single edit.
+func suggestNames(name string, candidates []string) []string {
+	threshold := len(name)/3 + 1
+	distances := map[string]int{}
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if strings.EqualFold(name, c) {
+			d = 1
+		}
+		if d <= threshold {
+			distances[c] = d
+		}
+	}
+
+	ss := make([]string, 0, len(distances))
+	for c := range distances {
+		ss = append(ss, c)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if distances[ss[i]] != distances[ss[j]] {
+			return distances[ss[i]] < distances[ss[j]]
+		}
+		return ss[i] < ss[j]
+	})
+	if len(ss) > 5 {
+		ss = ss[:5]
+	}
+	return ss
+}
+
+// didYouMean formats the suggestions to be appended to a message.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf(`"%s"`, s)
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf(", did you mean %s?", quoted[0])
+	}
+	return fmt.Sprintf(", did you mean %s or %s?", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
@@ -0,0 +1,236 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around a hunk.
+const diffContext = 3
+
+type diffOp byte
+
+const (
+	opEqual  diffOp = ' '
+	opDelete diffOp = '-'
+	opInsert diffOp = '+'
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// UnifiedDiff returns the differences between the texts a and b in the
+// unified format, or an empty string when they are the same.
+func UnifiedDiff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", aName, bName))
+
+	// aLine and bLine are the 1-based numbers of the next line of a and b
+	aLine, bLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].op == opEqual {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+
+		// the hunk starts with the context before the change
+		start := i
+		for start > 0 && i-start < diffContext && lines[start-1].op == opEqual {
+			start--
+		}
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+
+		// and extends until the changes are further apart than twice the
+		// context
+		end := i
+		for end < len(lines) {
+			if lines[end].op != opEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == opEqual {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				if next-end > diffContext {
+					end += diffContext
+				} else {
+					end = next
+				}
+				break
+			}
+			end = next
+		}
+
+		aCount, bCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.op != opInsert {
+				aCount++
+			}
+			if l.op != opDelete {
+				bCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)))
+		for _, l := range lines[start:end] {
+			sb.WriteByte(byte(l.op))
+			sb.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		for _, l := range lines[i:end] {
+			if l.op != opInsert {
+				aLine++
+			}
+			if l.op != opDelete {
+				bLine++
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// an empty range refers to the line before it
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines returns the lines of s with their line feed, so that a last
+// line without one differs from the same line with it.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b with the linear
+// space variant of Myers' algorithm: the middle snake of the script splits
+// it into two smaller ones, after the common prefix and suffix are left
+// out.
+func diffLines(a, b []string) []diffLine {
+	return appendDiffLines(make([]diffLine, 0, len(a)+len(b)), a, b)
+}
+
+func appendDiffLines(lines []diffLine, a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, l := range a[:prefix] {
+		lines = append(lines, diffLine{opEqual, l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(ma) == 0:
+		for _, l := range mb {
+			lines = append(lines, diffLine{opInsert, l})
+		}
+	case len(mb) == 0:
+		for _, l := range ma {
+			lines = append(lines, diffLine{opDelete, l})
+		}
+	default:
+		x, y, u, v := middleSnake(ma, mb)
+		lines = appendDiffLines(lines, ma[:x], mb[:y])
+		for _, l := range ma[x:u] {
+			lines = append(lines, diffLine{opEqual, l})
+		}
+		lines = appendDiffLines(lines, ma[u:], mb[v:])
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{opEqual, l})
+	}
+	return lines
+}
+
+// middleSnake returns the start (x, y) and the end (u, v) of the snake in
+// the middle of a shortest edit script from a to b, found by searching
+// from both ends at once. a and b are not empty, and their first lines and
+// their last lines differ, so that the parts before and after the snake
+// are smaller than the whole.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	max := (n + m + 1) / 2
+	delta := n - m
+	// forward[k] is the furthest x on the diagonal k = x - y from the
+	// start, and backward[k] the furthest distance from the end on the
+	// diagonal k of the reversed texts
+	offset := max + 1
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			forward[offset+k] = u
+
+			if r := delta - k; delta%2 != 0 && r >= -(d-1) && r <= d-1 && u+backward[offset+r] >= n {
+				return x, y, u, v
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var rx int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				rx = backward[offset+k+1]
+			} else {
+				rx = backward[offset+k-1] + 1
+			}
+			ry := rx - k
+			ru, rv := rx, ry
+			for ru < n && rv < m && a[n-1-ru] == b[m-1-rv] {
+				ru++
+				rv++
+			}
+			backward[offset+k] = ru
+
+			if f := delta - k; delta%2 == 0 && f >= -d && f <= d && ru+forward[offset+f] >= n {
+				return n - ru, m - rv, n - rx, m - ry
+			}
+		}
+	}
+	// not reached, the snakes always meet by then
+	return 0, 0, 0, 0
+}
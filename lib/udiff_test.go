@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "type Query {\n  a: A\n  b: B\n  c: C\n  d: D\n  e: E\n  f: F\n  g: G\n  h: H\n  i: I\n}\n"
+	b := "type Query {\n  a: A\n  bb: B\n  c: C\n  d: D\n  e: E\n  f: F\n  g: G\n  h: H\n  i: I\n  j: J\n}\n"
+
+	expected := `--- old
++++ new
+@@ -1,6 +1,6 @@
+ type Query {
+   a: A
+-  b: B
++  bb: B
+   c: C
+   d: D
+   e: E
+@@ -8,4 +8,5 @@
+   g: G
+   h: H
+   i: I
++  j: J
+ }
+`
+	if d := UnifiedDiff("old", "new", a, b); d != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, d)
+	}
+
+	if d := UnifiedDiff("old", "new", a, a); d != "" {
+		t.Fatalf("expected no difference, got\n%s", d)
+	}
+
+	expected = "--- old\n+++ new\n@@ -0,0 +1 @@\n+scalar Date\n"
+	if d := UnifiedDiff("old", "new", "", "scalar Date\n"); d != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, d)
+	}
+
+	expected = "--- old\n+++ new\n@@ -1,2 +1,2 @@\n scalar Date\n-scalar Time\n\\ No newline at end of file\n+scalar Time\n"
+	if d := UnifiedDiff("old", "new", "scalar Date\nscalar Time", "scalar Date\nscalar Time\n"); d != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, d)
+	}
+}
@@ -7,6 +7,11 @@ import (
 )
 
 func GetRelPath(absPath string) (*string, error) {
+	// a name which is not a path, e.g. "<stdin>", is kept as is
+	if !filepath.IsAbs(absPath) {
+		return &absPath, nil
+	}
+
 	base, err := filepath.Abs(".")
 	if err != nil {
 		return nil, fmt.Errorf("Error to get an absolute path")
@@ -212,3 +217,57 @@ func mergeDirectives(a, b []*Directive) []*Directive {
 
 	return merged
 }
+
+// deepCopy returns a copy of v where every pointer, slice and map
+// reachable from v is duplicated, so the copy can be mutated freely.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			// the unexported fields are not part of the definitions
+			if !c.Field(i).CanSet() {
+				continue
+			}
+			c.Field(i).Set(deepCopy(v.Field(i)))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// clone returns a deep copy of the schema. The sources are shared with
+// the original schema.
+func (s *Schema) clone() *Schema {
+	tmp := *s
+	tmp.Sources = nil
+	c := deepCopy(reflect.ValueOf(tmp)).Interface().(Schema)
+	c.Sources = s.Sources
+	return &c
+}
@@ -0,0 +1,395 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeAndValidate merges the GraphQL files like Merge and validates the
+// merged schema. The schema is generated even if there are errors. The
+// parse and merge errors are returned instead of panicking.
+func MergeAndValidate(indent string, paths ...string) (ss *string, ds Diagnostics, err error) {
+	defer recoverError(&err)
+
+	schema := mergePaths(MergeOptions{}, paths...)
+	if schema == nil {
+		return nil, nil, nil
+	}
+
+	ds = Validate(schema)
+
+	ms := MergedSchema{Indent: indent}
+	out := ms.WriteSchema(schema)
+	return &out, ds, nil
+}
+
+// UndefinedTypes resolves every named type referenced in the schema, i.e.
+// the types of fields, arguments and input fields, the union members, the
+// implemented interfaces and the operation root types, and reports the
+// undefined ones.
+func UndefinedTypes(s *Schema) Diagnostics {
+	ds := Diagnostics{}
+	for _, d := range Validate(s) {
+		if d.Rule == "undefined-type" {
+			ds = append(ds, d)
+		}
+	}
+	return ds
+}
+
+// Validate checks the merged schema against the type system validation
+// rules of the GraphQL specification.
+func Validate(s *Schema) Diagnostics {
+	v := validator{schema: s, index: s.index()}
+
+	v.validateSchemaDefinition()
+	v.validateTypeNames()
+	v.validateDirectiveDefinitions()
+	v.validateObjects()
+	v.validateInterfaces()
+	v.validateUnions()
+	v.validateEnums()
+	v.validateInputs()
+	v.validateDirectiveUsages()
+	v.validateDefaultValues()
+
+	v.diagnostics.sort()
+	return v.diagnostics
+}
+
+// directiveLocations are the valid locations of a directive definition.
+var directiveLocations = []string{
+	"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION",
+	"FRAGMENT_SPREAD", "INLINE_FRAGMENT", "VARIABLE_DEFINITION",
+	"SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION",
+	"INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+	"INPUT_FIELD_DEFINITION",
+}
+
+type validator struct {
+	schema      *Schema
+	index       *schemaIndex
+	diagnostics Diagnostics
+}
+
+func (v *validator) errorf(pos BaseFileInfo, rule string, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, &Diagnostic{
+		BaseFileInfo: pos,
+		Severity:     SeverityError,
+		Rule:         rule,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) checkName(pos BaseFileInfo, what, name string) {
+	if strings.HasPrefix(name, "__") {
+		v.errorf(pos, "reserved-name", `%s "%s" must not begin with "__", which is reserved for introspection`, what, name)
+	}
+}
+
+// checkTypeRef reports a reference to an undefined type, along with the
+// defined types it could be a typo of.
+func (v *validator) checkTypeRef(pos BaseFileInfo, name string) bool {
+	if v.index.kind(name) == kindUndefined {
+		v.errorf(pos, "undefined-type", `undefined type "%s"%s`, name, didYouMean(suggestNames(name, v.index.typeNames())))
+		return false
+	}
+	return true
+}
+
+func (v *validator) validateSchemaDefinition() {
+	for _, sd := range v.schema.SchemaDefinitions {
+		ops := []struct {
+			op   string
+			name *string
+		}{
+			{"query", sd.Query},
+			{"mutation", sd.Mutation},
+			{"subscription", sd.Subscription},
+		}
+		for _, o := range ops {
+			if o.name == nil {
+				continue
+			}
+			if !v.checkTypeRef(sd.BaseFileInfo, *o.name) {
+				continue
+			}
+			if k := v.index.kind(*o.name); k != kindObject {
+				v.errorf(sd.BaseFileInfo, "root-operation-type", `%s root type "%s" must be an object type, not %s`, o.op, *o.name, k)
+			}
+		}
+	}
+}
+
+// validateTypeNames checks that the named types are not reserved and are
+// not defined twice with different kinds, e.g. as a type and an enum.
+func (v *validator) validateTypeNames() {
+	type def struct {
+		pos  BaseFileInfo
+		kind typeKind
+	}
+	seen := map[string]def{}
+	add := func(pos BaseFileInfo, name string, kind typeKind) {
+		v.checkName(pos, "type", name)
+		if d, ok := seen[name]; ok {
+			v.errorf(pos, "unique-type-name", `type "%s" is already defined as %s at %s`, name, d.kind, d.pos.Position())
+			return
+		}
+		seen[name] = def{pos, kind}
+	}
+
+	for _, t := range v.schema.Types {
+		add(t.BaseFileInfo, t.Name, kindObject)
+	}
+	for _, i := range v.schema.Interfaces {
+		add(i.BaseFileInfo, i.Name, kindInterface)
+	}
+	for _, u := range v.schema.Unions {
+		add(u.BaseFileInfo, u.Name, kindUnion)
+	}
+	for _, e := range v.schema.Enums {
+		add(e.BaseFileInfo, e.Name, kindEnum)
+	}
+	for _, i := range v.schema.Inputs {
+		add(i.BaseFileInfo, i.Name, kindInput)
+	}
+	for _, c := range v.schema.Scalars {
+		add(c.BaseFileInfo, c.Name, kindScalar)
+	}
+}
+
+func (v *validator) validateDirectiveDefinitions() {
+	for _, d := range v.schema.DirectiveDefinitions {
+		v.checkName(d.BaseFileInfo, "directive", d.Name)
+		v.validateArgs(fmt.Sprintf("@%s", d.Name), d.Args)
+
+		if len(d.Locations) == 0 {
+			v.errorf(d.BaseFileInfo, "directive-location", `directive "@%s" must have at least one location`, d.Name)
+		}
+		for _, l := range d.Locations {
+			valid := false
+			for _, vl := range directiveLocations {
+				if l == vl {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				v.errorf(d.BaseFileInfo, "directive-location", `unknown location "%s" of directive "@%s"`, l, d.Name)
+			}
+		}
+	}
+}
+
+// validateArgs checks the arguments of a field or a directive definition.
+func (v *validator) validateArgs(owner string, args []*Arg) {
+	seen := map[string]bool{}
+	for _, a := range args {
+		v.checkName(a.BaseFileInfo, "argument", a.Name)
+		if seen[a.Name] {
+			v.errorf(a.BaseFileInfo, "unique-argument-name", `argument "%s" of "%s" is defined more than once`, a.Name, owner)
+		}
+		seen[a.Name] = true
+
+		if v.checkTypeRef(a.BaseFileInfo, a.Type) && !v.index.isInputType(a.Type) {
+			v.errorf(a.BaseFileInfo, "input-type", `argument "%s" of "%s" must be an input type, but "%s" is %s`, a.Name, owner, a.Type, v.index.kind(a.Type))
+		}
+	}
+}
+
+// validateOutputFields checks the fields of an object type or an interface.
+func (v *validator) validateOutputFields(pos BaseFileInfo, what, typ string, fields []*Field) {
+	if len(fields) == 0 {
+		v.errorf(pos, "empty-type", `%s "%s" must define at least one field`, what, typ)
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		v.checkName(f.BaseFileInfo, "field", f.Name)
+		if seen[f.Name] {
+			v.errorf(f.BaseFileInfo, "unique-field-name", `field "%s.%s" is defined more than once`, typ, f.Name)
+		}
+		seen[f.Name] = true
+
+		if v.checkTypeRef(f.BaseFileInfo, f.Type) && !v.index.isOutputType(f.Type) {
+			v.errorf(f.BaseFileInfo, "output-type", `field "%s.%s" must be an output type, but "%s" is %s`, typ, f.Name, f.Type, v.index.kind(f.Type))
+		}
+		v.validateArgs(typ+"."+f.Name, f.Args)
+	}
+}
+
+func (v *validator) validateObjects() {
+	for _, t := range v.schema.Types {
+		v.validateOutputFields(t.BaseFileInfo, "type", t.Name, t.Fields)
+
+		seen := map[string]bool{}
+		for _, it := range t.ImplTypes {
+			if seen[it] {
+				v.errorf(t.BaseFileInfo, "interface-implementation", `type "%s" implements "%s" more than once`, t.Name, it)
+				continue
+			}
+			seen[it] = true
+
+			if !v.checkTypeRef(t.BaseFileInfo, it) {
+				continue
+			}
+			iface, ok := v.index.interfaces[it]
+			if !ok {
+				v.errorf(t.BaseFileInfo, "interface-implementation", `type "%s" can only implement interfaces, but "%s" is %s`, t.Name, it, v.index.kind(it))
+				continue
+			}
+			v.validateImplementation(t, iface)
+		}
+	}
+}
+
+// validateImplementation checks that the object type provides every field
+// of the interface with a compatible type and the same arguments.
+func (v *validator) validateImplementation(t *Type, iface *Interface) {
+	fields := map[string]*Field{}
+	for _, f := range t.Fields {
+		fields[f.Name] = f
+	}
+
+	for _, iff := range iface.Fields {
+		f, ok := fields[iff.Name]
+		if !ok {
+			v.errorf(t.BaseFileInfo, "interface-implementation", `type "%s" must define field "%s" of interface "%s"`, t.Name, iff.Name, iface.Name)
+			continue
+		}
+
+		if !v.index.isSubType(fieldTypeRef(f), fieldTypeRef(iff)) {
+			v.errorf(f.BaseFileInfo, "interface-implementation", `field "%s.%s" of type "%s" is not compatible with "%s" of interface "%s"`, t.Name, f.Name, fieldTypeRef(f), fieldTypeRef(iff), iface.Name)
+		}
+
+		args := map[string]*Arg{}
+		for _, a := range f.Args {
+			args[a.Name] = a
+		}
+		for _, ia := range iff.Args {
+			a, ok := args[ia.Name]
+			if !ok {
+				v.errorf(f.BaseFileInfo, "interface-implementation", `field "%s.%s" must define argument "%s" of interface "%s"`, t.Name, f.Name, ia.Name, iface.Name)
+				continue
+			}
+			if argTypeRef(a) != argTypeRef(ia) {
+				v.errorf(a.BaseFileInfo, "interface-implementation", `argument "%s" of "%s.%s" must be "%s" like in interface "%s", not "%s"`, a.Name, t.Name, f.Name, argTypeRef(ia), iface.Name, argTypeRef(a))
+			}
+			delete(args, ia.Name)
+		}
+		for _, a := range f.Args {
+			if _, extra := args[a.Name]; extra && isRequiredArg(a) {
+				v.errorf(a.BaseFileInfo, "interface-implementation", `argument "%s" of "%s.%s" must be optional since it is not defined in interface "%s"`, a.Name, t.Name, f.Name, iface.Name)
+			}
+		}
+	}
+}
+
+// isRequiredArg reports whether the argument is non-null without a
+// default value.
+func isRequiredArg(a *Arg) bool {
+	return argTypeRef(a).nonNull() && a.DefaultValues == nil
+}
+
+func (v *validator) validateInterfaces() {
+	for _, i := range v.schema.Interfaces {
+		v.validateOutputFields(i.BaseFileInfo, "interface", i.Name, i.Fields)
+	}
+}
+
+func (v *validator) validateUnions() {
+	for _, u := range v.schema.Unions {
+		if len(u.Types) == 0 {
+			v.errorf(u.BaseFileInfo, "empty-type", `union "%s" must have at least one member`, u.Name)
+		}
+		seen := map[string]bool{}
+		for _, m := range u.Types {
+			if seen[m] {
+				v.errorf(u.BaseFileInfo, "unique-union-member", `union "%s" includes "%s" more than once`, u.Name, m)
+				continue
+			}
+			seen[m] = true
+
+			if v.checkTypeRef(u.BaseFileInfo, m) && v.index.kind(m) != kindObject {
+				v.errorf(u.BaseFileInfo, "union-member-type", `member "%s" of union "%s" must be an object type, not %s`, m, u.Name, v.index.kind(m))
+			}
+		}
+	}
+}
+
+func (v *validator) validateEnums() {
+	for _, e := range v.schema.Enums {
+		if len(e.EnumValues) == 0 {
+			v.errorf(e.BaseFileInfo, "empty-type", `enum "%s" must define at least one value`, e.Name)
+		}
+		seen := map[string]bool{}
+		for _, ev := range e.EnumValues {
+			v.checkName(ev.BaseFileInfo, "enum value", ev.Name)
+			if ev.Name == "true" || ev.Name == "false" || ev.Name == "null" {
+				v.errorf(ev.BaseFileInfo, "enum-value-name", `enum value "%s.%s" must not be true, false or null`, e.Name, ev.Name)
+			}
+			if seen[ev.Name] {
+				v.errorf(ev.BaseFileInfo, "unique-enum-value", `enum value "%s.%s" is defined more than once`, e.Name, ev.Name)
+			}
+			seen[ev.Name] = true
+		}
+	}
+}
+
+func (v *validator) validateInputs() {
+	for _, i := range v.schema.Inputs {
+		if len(i.Fields) == 0 {
+			v.errorf(i.BaseFileInfo, "empty-type", `input "%s" must define at least one field`, i.Name)
+		}
+		seen := map[string]bool{}
+		for _, f := range i.Fields {
+			v.checkName(f.BaseFileInfo, "input field", f.Name)
+			if seen[f.Name] {
+				v.errorf(f.BaseFileInfo, "unique-field-name", `input field "%s.%s" is defined more than once`, i.Name, f.Name)
+			}
+			seen[f.Name] = true
+
+			if v.checkTypeRef(f.BaseFileInfo, f.Type) && !v.index.isInputType(f.Type) {
+				v.errorf(f.BaseFileInfo, "input-type", `input field "%s.%s" must be an input type, but "%s" is %s`, i.Name, f.Name, f.Type, v.index.kind(f.Type))
+			}
+		}
+	}
+
+	v.validateInputCycles()
+}
+
+// validateInputCycles reports input objects referencing themselves through
+// non-null singular fields, which could never be provided.
+func (v *validator) validateInputCycles() {
+	visited := map[string]bool{}
+	for _, i := range v.schema.Inputs {
+		if visited[i.Name] {
+			continue
+		}
+		path := []string{}
+		onPath := map[string]int{}
+
+		var visit func(in *Input)
+		visit = func(in *Input) {
+			visited[in.Name] = true
+			onPath[in.Name] = len(path)
+			for _, f := range in.Fields {
+				if f.IsList || f.Null {
+					continue
+				}
+				next, ok := v.index.inputs[f.Type]
+				if !ok {
+					continue
+				}
+				path = append(path, in.Name+"."+f.Name)
+				if start, ok := onPath[next.Name]; ok {
+					v.errorf(next.BaseFileInfo, "input-object-cycle", `input "%s" cannot reference itself through non-null fields: %s`, next.Name, strings.Join(path[start:], ", "))
+				} else if !visited[next.Name] {
+					visit(next)
+				}
+				path = path[:len(path)-1]
+			}
+			delete(onPath, in.Name)
+		}
+		visit(i)
+	}
+}
@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"strings"
+	"sync"
+)
+
+// builtInDirectivesSDL defines the directives every GraphQL service
+// provides.
+const builtInDirectivesSDL = `
+directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @specifiedBy(url: String!) on SCALAR
+directive @oneOf on INPUT_OBJECT
+`
+
+var (
+	builtInDirectivesOnce sync.Once
+	builtInDirectives     map[string]*DirectiveDefinition
+)
+
+// builtInDirective returns the definition of a built-in directive, or nil.
+func builtInDirective(name string) *DirectiveDefinition {
+	builtInDirectivesOnce.Do(func() {
+		s := Schema{}
+		s.Parse(NewParser(strings.NewReader(builtInDirectivesSDL), "<built-in>"))
+		builtInDirectives = map[string]*DirectiveDefinition{}
+		for _, d := range s.DirectiveDefinitions {
+			builtInDirectives[d.Name] = d
+		}
+	})
+	return builtInDirectives[name]
+}
+
+// directiveDefinition returns the definition of the directive in the schema
+// or, if not defined, the built-in one.
+func (x *schemaIndex) directiveDefinition(name string) *DirectiveDefinition {
+	if d, ok := x.directives[name]; ok {
+		return d
+	}
+	return builtInDirective(name)
+}
+
+// validateDirectiveUsages checks every applied directive against its
+// definition.
+func (v *validator) validateDirectiveUsages() {
+	s := v.schema
+
+	for _, d := range s.DirectiveDefinitions {
+		for _, a := range d.Args {
+			v.validateDirectives(a.Directives, "ARGUMENT_DEFINITION")
+		}
+	}
+
+	fields := func(fs []*Field, location string) {
+		for _, f := range fs {
+			v.validateDirectives(f.Directives, location)
+			for _, a := range f.Args {
+				v.validateDirectives(a.Directives, "ARGUMENT_DEFINITION")
+			}
+		}
+	}
+
+	for _, t := range s.Types {
+		v.validateDirectives(t.Directives, "OBJECT")
+		fields(t.Fields, "FIELD_DEFINITION")
+	}
+	for _, i := range s.Interfaces {
+		v.validateDirectives(i.Directives, "INTERFACE")
+		fields(i.Fields, "FIELD_DEFINITION")
+	}
+	for _, u := range s.Unions {
+		v.validateDirectives(u.Directives, "UNION")
+	}
+	for _, e := range s.Enums {
+		v.validateDirectives(e.Directives, "ENUM")
+		for _, ev := range e.EnumValues {
+			v.validateDirectives(ev.Directives, "ENUM_VALUE")
+		}
+	}
+	for _, i := range s.Inputs {
+		v.validateDirectives(i.Directives, "INPUT_OBJECT")
+		fields(i.Fields, "INPUT_FIELD_DEFINITION")
+	}
+	for _, c := range s.Scalars {
+		v.validateDirectives(c.Directives, "SCALAR")
+	}
+}
+
+// validateDirectives checks the directives applied at the same location.
+func (v *validator) validateDirectives(ds []*Directive, location string) {
+	seen := map[string]bool{}
+	for _, d := range ds {
+		def := v.index.directiveDefinition(d.Name)
+		if def == nil {
+			names := []string{}
+			for n := range v.index.directives {
+				names = append(names, "@"+n)
+			}
+			for n := range builtInDirectives {
+				if _, ok := v.index.directives[n]; !ok {
+					names = append(names, "@"+n)
+				}
+			}
+			v.errorf(d.BaseFileInfo, "known-directive", `unknown directive "@%s"%s`, d.Name, didYouMean(suggestNames("@"+d.Name, names)))
+			continue
+		}
+
+		allowed := false
+		for _, l := range def.Locations {
+			if l == location {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			v.errorf(d.BaseFileInfo, "directive-location", `directive "@%s" may not be used on %s`, d.Name, location)
+		}
+
+		if seen[d.Name] && !def.Repeatable {
+			v.errorf(d.BaseFileInfo, "unique-directive", `directive "@%s" can only be used once at this location`, d.Name)
+		}
+		seen[d.Name] = true
+
+		v.validateDirectiveArgs(d, def)
+	}
+}
+
+func (v *validator) validateDirectiveArgs(d *Directive, def *DirectiveDefinition) {
+	defs := map[string]*Arg{}
+	names := []string{}
+	for _, a := range def.Args {
+		defs[a.Name] = a
+		names = append(names, a.Name)
+	}
+
+	given := map[string]bool{}
+	for _, da := range d.DirectiveArgs {
+		if given[da.Name] {
+			v.errorf(d.BaseFileInfo, "unique-argument-name", `argument "%s" of directive "@%s" is given more than once`, da.Name, d.Name)
+			continue
+		}
+		given[da.Name] = true
+
+		a, ok := defs[da.Name]
+		if !ok {
+			v.errorf(d.BaseFileInfo, "known-argument", `unknown argument "%s" of directive "@%s"%s`, da.Name, d.Name, didYouMean(suggestNames(da.Name, names)))
+			continue
+		}
+
+		val := parseLiterals(da.Value, da.IsList)
+		if val == nil {
+			continue
+		}
+		if msg := v.index.checkValue(val, argTypeRef(a)); msg != "" {
+			v.errorf(d.BaseFileInfo, "argument-value", `invalid value of argument "%s" of directive "@%s": %s`, da.Name, d.Name, msg)
+		}
+	}
+
+	for _, a := range def.Args {
+		if isRequiredArg(a) && !given[a.Name] {
+			v.errorf(d.BaseFileInfo, "required-argument", `directive "@%s" requires argument "%s" of type %s`, d.Name, a.Name, argTypeRef(a))
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var src = `
+	schema {
+		query: Query
+		mutation: Payload
+	}
+
+	type Query {
+		user(id: ID!, id: ID): User
+		search(filter: User): [Result]
+		node: Node
+		__secret: String
+	}
+
+	interface Node {
+		id: ID!
+		parent(depth: Int): Node
+	}
+
+	type User implements Node {
+		id: ID
+		parent(depth: Int, recursive: Boolean!): User
+		address: Addres
+	}
+
+	union Payload = User | Node
+
+	union Result = User
+
+	enum Flag {
+		true
+		ON
+	}
+
+	input A {
+		b: B!
+	}
+
+	input B {
+		a: A!
+		payload: Result
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "schema.graphql")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	ds := Validate(merged)
+	if !ds.HasErrors() {
+		t.Fatal("should have errors")
+	}
+
+	expected := []string{
+		`mutation root type "Payload" must be an object type`,
+		`argument "id" of "Query.user" is defined more than once`,
+		`argument "filter" of "Query.search" must be an input type`,
+		`field "__secret" must not begin with "__"`,
+		`field "User.id" of type "ID" is not compatible with "ID!"`,
+		`argument "recursive" of "User.parent" must be optional`,
+		`undefined type "Addres"`,
+		`member "Node" of union "Payload" must be an object type`,
+		`enum value "Flag.true" must not be true, false or null`,
+		`cannot reference itself through non-null fields`,
+		`input field "B.payload" must be an input type`,
+	}
+
+	messages := []string{}
+	for _, d := range ds {
+		messages = append(messages, d.String())
+	}
+	all := strings.Join(messages, "\n")
+
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Fatalf("expected %q in\n%s", e, all)
+		}
+	}
+	if strings.Contains(all, `field "User.parent" of type`) {
+		t.Fatalf("covariant field type should be valid\n%s", all)
+	}
+}
+
+func TestUndefinedTypes(t *testing.T) {
+	var src = `
+	schema {
+		query: Querry
+	}
+
+	type Query {
+		me: Usr
+		posts(order: PostOrdr): [Post!]!
+	}
+
+	type User implements Nod {
+		id: ID!
+	}
+
+	type Post {
+		id: ID!
+		author: User
+		count: Strng
+	}
+
+	union Feed = Post | Users
+
+	enum PostOrder {
+		NEWEST
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "schema.graphql")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	expected := []string{
+		`schema.graphql:2:8: error: undefined type "Querry", did you mean "Query"?`,
+		`schema.graphql:7:0: error: undefined type "Usr", did you mean "User"?`,
+		`undefined type "PostOrdr", did you mean "PostOrder"`,
+		`undefined type "Nod"`,
+		`undefined type "Strng", did you mean "String"?`,
+		`undefined type "Users", did you mean "User"?`,
+	}
+
+	ds := UndefinedTypes(merged)
+	if len(ds) != len(expected) {
+		t.Fatalf("expected %d undefined types, got %v", len(expected), ds)
+	}
+	for i, e := range expected {
+		if !strings.Contains(ds[i].String(), e) {
+			t.Fatalf("expected %q, got %q", e, ds[i])
+		}
+	}
+}
+
+func TestSuggestNames(t *testing.T) {
+	got := suggestNames("Usr", []string{"User", "Users", "Query", "user"})
+	if len(got) != 3 || got[0] != "User" || got[1] != "user" {
+		t.Fatalf("unexpected suggestions %v", got)
+	}
+}
+
+func TestValidateDirectiveUsages(t *testing.T) {
+	var src = `
+	directive @auth(role: Role!, scopes: [String!]) on FIELD_DEFINITION | OBJECT
+	directive @tag(name: String!) repeatable on FIELD_DEFINITION | OBJECT
+	directive @limit(max: Int) on FIELD_DEFINITION
+	directive @cache(options: CacheOptions) on FIELD_DEFINITION
+
+	type Query @auth(role: ADMIN) @auth(role: STAFF) {
+		me: String @auth(role: ADMINN)
+		users: [String] @auth(scopes: "read") @tag(name: "a") @tag(name: "b")
+		posts: [String] @limit(max: "ten") @limit(maxx: 10) @deprecated(reason: "use feed")
+		feed: [String] @auth(role: ADMIN, scopes: ["read", 1])
+		old: String @deprecatd
+		cached: String @cache(options: {ttl: 10})
+		uncached: String @cache(options: {ttl: "ten"})
+	}
+
+	input CacheOptions {
+		ttl: Int
+	}
+
+	enum Role @deprecated {
+		ADMIN
+		STAFF
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "schema.graphql")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	expected := []string{
+		`directive "@auth" can only be used once at this location`,
+		`value "ADMINN" does not exist in enum "Role", did you mean "ADMIN"?`,
+		`directive "@auth" requires argument "role" of type Role!`,
+		`invalid value of argument "max" of directive "@limit": expected Int, found "ten"`,
+		`unknown argument "maxx" of directive "@limit", did you mean "max"?`,
+		`directive "@limit" can only be used once at this location`,
+		`invalid value of argument "scopes" of directive "@auth": at index 1, expected String, found 1`,
+		`unknown directive "@deprecatd", did you mean "@deprecated"?`,
+		`directive "@deprecated" may not be used on ENUM`,
+		`invalid value of argument "options" of directive "@cache": at field CacheOptions.ttl, expected Int, found "ten"`,
+	}
+
+	messages := []string{}
+	for _, d := range Validate(merged) {
+		messages = append(messages, d.String())
+	}
+	all := strings.Join(messages, "\n")
+
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Fatalf("expected %q in\n%s", e, all)
+		}
+	}
+	for _, unexpected := range []string{`"@tag" can only be used once`, `argument "scopes" of directive "@auth": expected`} {
+		if strings.Contains(all, unexpected) {
+			t.Fatalf("unexpected %q in\n%s", unexpected, all)
+		}
+	}
+	if n := strings.Count(all, `directive "@cache"`); n != 1 {
+		t.Fatalf("expected a single @cache problem, found %d in\n%s", n, all)
+	}
+}
+
+func TestValidateDefaultValues(t *testing.T) {
+	var src = `
+	type Query {
+		users(limit: Int = "ten", offset: Int = 0, ratio: Float = 0.5, role: Role = ADMINN): [String]
+		posts(tags: [String] = "news", ids: [ID!] = [1, "2", null], order: Order = {field: CREATED, direction: DESC}): [String]
+		feed(order: Order = {direction: ASC}, first: Int! = null, big: Int = 3000000000): [String]
+		search(order: Order = {field: CREATED, dir: ASC}, scale: Float = 1.5e3): [String]
+		list(x: [Int!] = null, y: [Int] = 5): [String]
+	}
+
+	enum Role {
+		ADMIN
+	}
+
+	enum Field {
+		CREATED
+	}
+
+	enum Direction {
+		ASC
+		DESC
+	}
+
+	input Order {
+		field: Field!
+		direction: Direction = ASC
+		weight: Float = 1
+		xs: [String!] = null
+	}
+`
+
+	s := Schema{}
+	p := NewParser(strings.NewReader(src), "schema.graphql")
+	s.Parse(p)
+	merged := mergeSchemas([]Schema{s}, ConflictError)
+
+	expected := []string{
+		`invalid default value of argument "limit" of "Query.users": expected Int, found "ten"`,
+		`invalid default value of argument "role" of "Query.users": value "ADMINN" does not exist in enum "Role", did you mean "ADMIN"?`,
+		`invalid default value of argument "ids" of "Query.posts": at index 2, expected non-null ID!, found null`,
+		`invalid default value of argument "order" of "Query.feed": field "field" of type Field! is required by input object Order`,
+		`invalid default value of argument "first" of "Query.feed": expected non-null Int!, found null`,
+		`invalid default value of argument "big" of "Query.feed": Int cannot represent 3000000000`,
+		`invalid default value of argument "order" of "Query.search": field "dir" is not defined by input object Order`,
+	}
+
+	ds := Validate(merged)
+	messages := []string{}
+	for _, d := range ds {
+		messages = append(messages, d.String())
+	}
+	all := strings.Join(messages, "\n")
+
+	for _, e := range expected {
+		if !strings.Contains(all, e) {
+			t.Fatalf("expected %q in\n%s", e, all)
+		}
+	}
+	if len(ds) != len(expected) {
+		t.Fatalf("expected %d problems, got\n%s", len(expected), all)
+	}
+
+	ms := MergedSchema{Indent: "  "}
+	out := ms.WriteSchema(merged)
+	for _, printed := range []string{`ratio: Float = 0.5`, `order: Order = {field: CREATED, direction: DESC}`, `tags: [String] = "news"`, `scale: Float = 1.5e3`, `x: [Int!] = null`, `y: [Int] = 5`, `xs: [String!] = null`} {
+		if !strings.Contains(out, printed) {
+			t.Fatalf("expected %q in\n%s", printed, out)
+		}
+	}
+}
+
+func TestMergeAndValidateConflict(t *testing.T) {
+	if _, _, err := MergeAndValidate("  ", conflictingDir(t)); err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+}
@@ -0,0 +1,43 @@
+package lib
+
+// validateDefaultValues checks the default values of the arguments and the
+// input fields against their types.
+func (v *validator) validateDefaultValues() {
+	args := func(owner string, as []*Arg) {
+		for _, a := range as {
+			v.checkDefaultValue(a.BaseFileInfo, `argument "`+a.Name+`" of "`+owner+`"`, a.DefaultValues, argTypeRef(a))
+		}
+	}
+
+	for _, d := range v.schema.DirectiveDefinitions {
+		args("@"+d.Name, d.Args)
+	}
+	for _, t := range v.schema.Types {
+		for _, f := range t.Fields {
+			args(t.Name+"."+f.Name, f.Args)
+		}
+	}
+	for _, i := range v.schema.Interfaces {
+		for _, f := range i.Fields {
+			args(i.Name+"."+f.Name, f.Args)
+		}
+	}
+	for _, i := range v.schema.Inputs {
+		for _, f := range i.Fields {
+			v.checkDefaultValue(f.BaseFileInfo, `input field "`+i.Name+"."+f.Name+`"`, f.DefaultValues, fieldTypeRef(f))
+		}
+	}
+}
+
+func (v *validator) checkDefaultValue(pos BaseFileInfo, what string, values *[]string, ref typeRef) {
+	if values == nil {
+		return
+	}
+	val := parseDefaultValue(*values)
+	if val == nil {
+		return
+	}
+	if msg := v.index.checkValue(val, ref); msg != "" {
+		v.errorf(pos, "default-value", `invalid default value of %s: %s`, what, msg)
+	}
+}
@@ -0,0 +1,276 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type valueKind int
+
+const (
+	valueNull valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueEnum
+	valueList
+	valueObject
+)
+
+// value is a literal value of a directive argument or a default value.
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*objectField
+}
+
+type objectField struct {
+	name  string
+	value *value
+}
+
+func (v *value) String() string {
+	switch v.kind {
+	case valueList:
+		ss := make([]string, len(v.list))
+		for i, e := range v.list {
+			ss[i] = e.String()
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+	case valueObject:
+		ss := make([]string, len(v.fields))
+		for i, f := range v.fields {
+			ss[i] = f.name + ": " + f.value.String()
+		}
+		return "{" + strings.Join(ss, ", ") + "}"
+	}
+	return v.raw
+}
+
+// parseLiteral returns the value of a single literal as it is stored by the
+// parser, e.g. `"text"`, `20`, `ADMIN` or `{name: "text"}`.
+func parseLiteral(raw string) *value {
+	if strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") {
+		l := newLexer(strings.NewReader(raw), "")
+		return parseCompositeLiteral(l, l.next())
+	}
+
+	v := &value{raw: raw}
+	switch {
+	case raw == "null":
+		v.kind = valueNull
+	case raw == "true" || raw == "false":
+		v.kind = valueBoolean
+	case strings.HasPrefix(raw, `"`):
+		v.kind = valueString
+	case isIntLiteral(raw):
+		v.kind = valueInt
+	case isFloatLiteral(raw):
+		v.kind = valueFloat
+	default:
+		v.kind = valueEnum
+	}
+	return v
+}
+
+// parseCompositeLiteral parses a list or an input object literal whose
+// first token has been read already.
+func parseCompositeLiteral(l *lexer, tok *token) *value {
+	switch tok.typ {
+	case tokLBracket:
+		v := &value{kind: valueList}
+		for {
+			tok = l.next()
+			switch tok.typ {
+			case tokComma:
+				continue
+			case tokRBracket, tokEOF:
+				return v
+			}
+			v.list = append(v.list, parseCompositeLiteral(l, tok))
+		}
+	case tokLBrace:
+		v := &value{kind: valueObject}
+		for {
+			tok = l.next()
+			switch tok.typ {
+			case tokComma:
+				continue
+			case tokRBrace, tokEOF:
+				return v
+			}
+			name := tok.String()
+			l.consumeToken(tokColon)
+			v.fields = append(v.fields, &objectField{name: name, value: parseCompositeLiteral(l, l.next())})
+		}
+	}
+	return parseLiteral(tok.String())
+}
+
+// parseDefaultValue returns the value of a default value, which the parser
+// stores as the single literal written in the schema, e.g. `[1, 2]` or
+// `null` for a list.
+func parseDefaultValue(values []string) *value {
+	if len(values) == 0 {
+		return nil
+	}
+	return parseLiteral(values[0])
+}
+
+// parseLiterals returns the value of literals stored as a list, e.g. the
+// value of a directive argument.
+func parseLiterals(raws []string, isList bool) *value {
+	if !isList {
+		if len(raws) == 0 {
+			return nil
+		}
+		return parseLiteral(raws[0])
+	}
+	v := &value{kind: valueList}
+	for _, r := range raws {
+		v.list = append(v.list, parseLiteral(r))
+	}
+	return v
+}
+
+var (
+	intLiteral   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	floatLiteral = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+func isIntLiteral(raw string) bool {
+	return intLiteral.MatchString(raw)
+}
+
+func isFloatLiteral(raw string) bool {
+	return floatLiteral.MatchString(raw)
+}
+
+// checkValue returns a message describing why the value cannot be coerced
+// to the type, or an empty string when it can. A single value is coerced
+// to a list of one element.
+func (x *schemaIndex) checkValue(v *value, ref typeRef) string {
+	if v.kind == valueNull {
+		if ref.nonNull() {
+			return fmt.Sprintf(`expected non-null %s, found null`, ref)
+		}
+		return ""
+	}
+
+	if ref.IsList {
+		elem := typeRef{Name: ref.Name, Null: ref.Null}
+		if v.kind != valueList {
+			return x.checkValue(v, elem)
+		}
+		for i, e := range v.list {
+			if msg := x.checkValue(e, elem); msg != "" {
+				return fmt.Sprintf("at index %d, %s", i, msg)
+			}
+		}
+		return ""
+	}
+
+	if v.kind == valueList {
+		return fmt.Sprintf(`expected %s, found a list %s`, ref, v)
+	}
+
+	return x.checkNamedValue(v, ref.Name)
+}
+
+// checkNamedValue checks a non-null and non-list value against a named type.
+func (x *schemaIndex) checkNamedValue(v *value, name string) string {
+	mismatch := fmt.Sprintf(`expected %s, found %s`, name, v)
+
+	switch x.kind(name) {
+	case kindScalar:
+		switch name {
+		case "Int":
+			n, err := strconv.ParseInt(v.raw, 10, 64)
+			if v.kind != valueInt {
+				return mismatch
+			}
+			if err != nil || n > math.MaxInt32 || n < math.MinInt32 {
+				return fmt.Sprintf(`Int cannot represent %s, which is not a 32-bit signed integer`, v)
+			}
+		case "Float":
+			if v.kind != valueInt && v.kind != valueFloat {
+				return mismatch
+			}
+		case "String":
+			if v.kind != valueString {
+				return mismatch
+			}
+		case "Boolean":
+			if v.kind != valueBoolean {
+				return mismatch
+			}
+		case "ID":
+			if v.kind != valueString && v.kind != valueInt {
+				return mismatch
+			}
+		}
+		// custom scalars accept any literal
+		return ""
+
+	case kindEnum:
+		if v.kind != valueEnum {
+			return mismatch
+		}
+		values := []string{}
+		for _, ev := range x.enums[name].EnumValues {
+			if ev.Name == v.raw {
+				return ""
+			}
+			values = append(values, ev.Name)
+		}
+		return fmt.Sprintf(`value "%s" does not exist in enum "%s"%s`, v.raw, name, didYouMean(suggestNames(v.raw, values)))
+
+	case kindInput:
+		if v.kind != valueObject {
+			return fmt.Sprintf(`expected input object %s, found %s`, name, v)
+		}
+		return x.checkObjectValue(v, x.inputs[name])
+	}
+
+	// undefined types are reported on their own
+	return ""
+}
+
+// checkObjectValue checks the fields of an input object value, including
+// the required ones which are missing.
+func (x *schemaIndex) checkObjectValue(v *value, in *Input) string {
+	fields := map[string]*Field{}
+	names := []string{}
+	for _, f := range in.Fields {
+		fields[f.Name] = f
+		names = append(names, f.Name)
+	}
+
+	given := map[string]bool{}
+	for _, of := range v.fields {
+		if given[of.name] {
+			return fmt.Sprintf(`field "%s" of input object %s is given more than once`, of.name, in.Name)
+		}
+		given[of.name] = true
+
+		f, ok := fields[of.name]
+		if !ok {
+			return fmt.Sprintf(`field "%s" is not defined by input object %s%s`, of.name, in.Name, didYouMean(suggestNames(of.name, names)))
+		}
+		if msg := x.checkValue(of.value, fieldTypeRef(f)); msg != "" {
+			return fmt.Sprintf("at field %s.%s, %s", in.Name, of.name, msg)
+		}
+	}
+
+	for _, f := range in.Fields {
+		if ref := fieldTypeRef(f); ref.nonNull() && f.DefaultValues == nil && !given[f.Name] {
+			return fmt.Sprintf(`field "%s" of type %s is required by input object %s`, f.Name, ref, in.Name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NodeKind is the kind of a node of the schema, i.e. of the value behind
+// Cursor.Node.
+type NodeKind int
+
+const (
+	KindSchemaDefinition    NodeKind = iota // *SchemaDefinition
+	KindDirectiveDefinition                 // *DirectiveDefinition
+	KindType                                // *Type
+	KindScalar                              // *Scalar
+	KindEnum                                // *Enum
+	KindEnumValue                           // *EnumValue
+	KindInterface                           // *Interface
+	KindUnion                               // *Union
+	KindInput                               // *Input
+	KindField                               // *Field, of a type, interface or input
+	KindArg                                 // *Arg, of a field or directive definition
+	KindDirective                           // *Directive, applied to any node
+	KindDirectiveArg                        // *DirectiveArg
+)
+
+// Action tells Walk how to go on after a callback.
+type Action int
+
+const (
+	// Continue walks the children of the node, then its siblings.
+	Continue Action = iota
+	// SkipChildren, returned by Enter, neither walks the children of the
+	// node nor calls Leave for it.
+	SkipChildren
+	// Break stops the walk.
+	Break
+)
+
+// VisitFuncs are the callbacks of a node kind. Enter is called before the
+// children of the node are walked, and Leave after. Either can be nil.
+type VisitFuncs struct {
+	Enter func(c *Cursor) Action
+	Leave func(c *Cursor) Action
+}
+
+// Visitor holds the callbacks by node kind. The nodes of the other kinds
+// are walked through without any callback.
+type Visitor map[NodeKind]VisitFuncs
+
+// Cursor describes the node being walked. The node can be replaced or
+// deleted from its parent through the cursor.
+type Cursor struct {
+	node    interface{}
+	kind    NodeKind
+	parent  interface{}
+	deleted bool
+}
+
+// Node returns the node, e.g. a *Type for KindType.
+func (c *Cursor) Node() interface{} {
+	return c.node
+}
+
+func (c *Cursor) Kind() NodeKind {
+	return c.kind
+}
+
+// Parent returns the node the node belongs to, or the *Schema for the
+// definitions.
+func (c *Cursor) Parent() interface{} {
+	return c.parent
+}
+
+// Replace replaces the node with n, of the same type. When it is called
+// by Enter, the children of n are walked instead.
+func (c *Cursor) Replace(n interface{}) {
+	if reflect.TypeOf(n) != reflect.TypeOf(c.node) || reflect.ValueOf(n).IsNil() {
+		panic(fmt.Sprintf("cannot replace %T with %T", c.node, n))
+	}
+	c.node = n
+	c.deleted = false
+}
+
+// Delete removes the node from its parent. When it is called by Enter, the
+// children of the node are not walked.
+func (c *Cursor) Delete() {
+	c.deleted = true
+}
+
+// Walk walks the nodes of the schema depth first, in the order of the
+// printed schema, and calls the callbacks of the visitor. The nodes
+// replaced or deleted through the cursor are updated in the schema.
+func Walk(s *Schema, v Visitor) {
+	w := &walker{v: v}
+
+	s.SchemaDefinitions = walkList(w, KindSchemaDefinition, s, s.SchemaDefinitions, func(*SchemaDefinition) {})
+	s.DirectiveDefinitions = walkList(w, KindDirectiveDefinition, s, s.DirectiveDefinitions, func(d *DirectiveDefinition) {
+		d.Args = w.args(d, d.Args)
+	})
+	s.Types = walkList(w, KindType, s, s.Types, func(t *Type) {
+		t.Directives = w.directives(t, t.Directives)
+		t.Fields = w.fields(t, t.Fields)
+	})
+	s.Scalars = walkList(w, KindScalar, s, s.Scalars, func(sc *Scalar) {
+		sc.Directives = w.directives(sc, sc.Directives)
+	})
+	s.Enums = walkList(w, KindEnum, s, s.Enums, func(e *Enum) {
+		e.Directives = w.directives(e, e.Directives)
+		e.EnumValues = w.enumValues(e, e.EnumValues)
+	})
+	s.Interfaces = walkList(w, KindInterface, s, s.Interfaces, func(i *Interface) {
+		i.Directives = w.directives(i, i.Directives)
+		i.Fields = w.fields(i, i.Fields)
+	})
+	s.Unions = walkList(w, KindUnion, s, s.Unions, func(u *Union) {
+		u.Directives = w.directives(u, u.Directives)
+	})
+	s.Inputs = walkList(w, KindInput, s, s.Inputs, func(i *Input) {
+		i.Directives = w.directives(i, i.Directives)
+		i.Fields = w.fields(i, i.Fields)
+	})
+}
+
+type walker struct {
+	v    Visitor
+	stop bool
+}
+
+// visit calls the callbacks of the node around walking its children, and
+// returns the node to keep in its place, or nil when it is deleted.
+func (w *walker) visit(kind NodeKind, parent, node interface{}, children func(node interface{})) interface{} {
+	if w.stop {
+		return node
+	}
+
+	fs := w.v[kind]
+	c := &Cursor{node: node, kind: kind, parent: parent}
+
+	if fs.Enter != nil {
+		action := fs.Enter(c)
+		if c.deleted {
+			return nil
+		}
+		switch action {
+		case Break:
+			w.stop = true
+			return c.node
+		case SkipChildren:
+			return c.node
+		}
+	}
+
+	children(c.node)
+
+	if fs.Leave != nil && !w.stop {
+		if fs.Leave(c) == Break {
+			w.stop = true
+		}
+		if c.deleted {
+			return nil
+		}
+	}
+	return c.node
+}
+
+// walkList walks the nodes of a list and returns the list without the
+// deleted ones.
+func walkList[T any](w *walker, kind NodeKind, parent interface{}, list []*T, children func(*T)) []*T {
+	kept := list[:0]
+	for _, n := range list {
+		if r := w.visit(kind, parent, n, func(n interface{}) { children(n.(*T)) }); r != nil {
+			kept = append(kept, r.(*T))
+		}
+	}
+	return kept
+}
+
+func (w *walker) fields(parent interface{}, fs []*Field) []*Field {
+	return walkList(w, KindField, parent, fs, func(f *Field) {
+		f.Args = w.args(f, f.Args)
+		f.Directives = w.directives(f, f.Directives)
+	})
+}
+
+func (w *walker) args(parent interface{}, as []*Arg) []*Arg {
+	return walkList(w, KindArg, parent, as, func(a *Arg) {
+		a.Directives = w.directives(a, a.Directives)
+	})
+}
+
+func (w *walker) directives(parent interface{}, ds []*Directive) []*Directive {
+	return walkList(w, KindDirective, parent, ds, func(d *Directive) {
+		d.DirectiveArgs = walkList(w, KindDirectiveArg, d, d.DirectiveArgs, func(*DirectiveArg) {})
+	})
+}
+
+// enumValues walks the values, which are not kept by pointer in the enum.
+func (w *walker) enumValues(parent *Enum, vs []EnumValue) []EnumValue {
+	kept := vs[:0]
+	for i := range vs {
+		r := w.visit(KindEnumValue, parent, &vs[i], func(n interface{}) {
+			v := n.(*EnumValue)
+			v.Directives = w.directives(v, v.Directives)
+		})
+		if r != nil {
+			kept = append(kept, *r.(*EnumValue))
+		}
+	}
+	return kept
+}
@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	src := `
+type Query {
+  user(id: ID! @deprecated): User @auth
+  legacy: String
+}
+
+type User @key(fields: "id") {
+  id: ID!
+  role: Role
+}
+
+enum Role {
+  ADMIN
+  GUEST
+}
+`
+	parse := func() *Schema {
+		s := &Schema{}
+		s.Parse(NewParser(strings.NewReader(src), "schema.graphql"))
+		return s
+	}
+
+	// the nodes are entered and left depth first
+	events := []string{}
+	Walk(parse(), Visitor{
+		KindType: {
+			Enter: func(c *Cursor) Action {
+				events = append(events, "enter "+c.Node().(*Type).Name)
+				return Continue
+			},
+			Leave: func(c *Cursor) Action {
+				events = append(events, "leave "+c.Node().(*Type).Name)
+				return Continue
+			},
+		},
+		KindField: {Enter: func(c *Cursor) Action {
+			events = append(events, c.Parent().(*Type).Name+"."+c.Node().(*Field).Name)
+			return SkipChildren
+		}},
+		KindDirective: {Enter: func(c *Cursor) Action {
+			events = append(events, "@"+c.Node().(*Directive).Name)
+			return Continue
+		}},
+	})
+	expected := "enter Query, Query.user, Query.legacy, leave Query, enter User, @key, User.id, User.role, leave User"
+	if got := strings.Join(events, ", "); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	// the nodes are replaced and deleted
+	s := parse()
+	Walk(s, Visitor{
+		KindField: {Enter: func(c *Cursor) Action {
+			f := c.Node().(*Field)
+			switch f.Name {
+			case "legacy":
+				c.Delete()
+			case "role":
+				r := *f
+				r.Name = "roles"
+				r.IsList = true
+				c.Replace(&r)
+			}
+			return Continue
+		}},
+		KindDirective: {Leave: func(c *Cursor) Action {
+			if c.Node().(*Directive).Name == "deprecated" {
+				c.Delete()
+			}
+			return Continue
+		}},
+		KindEnumValue: {Enter: func(c *Cursor) Action {
+			if c.Node().(*EnumValue).Name == "GUEST" {
+				c.Delete()
+			}
+			return Continue
+		}},
+	})
+	out := printSchema(t, s, PrintOptions{Indent: "  "})
+	for _, want := range []string{"user(id: ID!): User @auth", "roles: [Role]", "ADMIN"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"legacy", "GUEST", "@deprecated", "role:"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in\n%s", unwanted, out)
+		}
+	}
+
+	// the walk stops on Break
+	count := 0
+	Walk(parse(), Visitor{KindField: {Enter: func(c *Cursor) Action {
+		count++
+		return Break
+	}}})
+	if count != 1 {
+		t.Errorf("expected the walk to stop at the first field, got %d fields", count)
+	}
+}
@@ -0,0 +1,177 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Watcher keeps the GraphQL files found in the paths parsed, re-parsing
+// only the files which are added or changed since the last poll. It polls
+// the file system instead of relying on notifications, so it works the
+// same everywhere without any dependency.
+type Watcher struct {
+	opts  MergeOptions
+	paths []string
+	files map[string]*watchedFile
+	// order lists the files found in each path by the last poll, in the
+	// order of the merge
+	order   [][]string
+	ignored map[string]bool
+}
+
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	schema  *Schema
+	err     error
+}
+
+func NewWatcher(paths ...string) *Watcher {
+	return NewWatcherWith(MergeOptions{}, paths...)
+}
+
+// NewWatcherWith returns a watcher which selects and merges the files with
+// the options.
+func NewWatcherWith(opts MergeOptions, paths ...string) *Watcher {
+	return &Watcher{opts: opts, paths: paths, files: map[string]*watchedFile{}, ignored: map[string]bool{}}
+}
+
+// Ignore leaves the files out of the watched ones, e.g. the outputs written
+// into a watched directory, which would be merged into themselves.
+func (w *Watcher) Ignore(files ...string) error {
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return err
+		}
+		w.ignored[abs] = true
+	}
+	return nil
+}
+
+// Poll looks for the added, changed and removed files and returns their
+// names, sorted. The added and changed files are parsed again.
+func (w *Watcher) Poll() ([]string, error) {
+	seen := map[string]bool{}
+	changed := []string{}
+	order := make([][]string, len(w.paths))
+
+	for i, path := range w.paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		err = walkSchemaFiles(w.opts.Filter, abs, func(p string, info os.FileInfo) error {
+			if w.ignored[p] {
+				return nil
+			}
+			order[i] = append(order[i], p)
+			if seen[p] {
+				return nil
+			}
+			seen[p] = true
+			f, ok := w.files[p]
+			if ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+				return nil
+			}
+
+			f = &watchedFile{modTime: info.ModTime(), size: info.Size()}
+			f.schema, f.err = parseFile(p)
+			w.files[p] = f
+			changed = append(changed, p)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for p := range w.files {
+		if !seen[p] {
+			delete(w.files, p)
+			changed = append(changed, p)
+		}
+	}
+
+	w.order = order
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// Schema merges the files parsed by the last poll. It returns an error
+// when a file cannot be parsed or the files cannot be merged, and nil
+// when no file is found.
+func (w *Watcher) Schema() (schema *Schema, err error) {
+	errs := []error{}
+	reported := map[string]bool{}
+	// a schema per path, as when the paths are merged once
+	schemas := make([]Schema, 0, len(w.order))
+	for _, names := range w.order {
+		sc := &Schema{}
+		for _, p := range names {
+			f := w.files[p]
+			if f.err != nil {
+				if !reported[p] {
+					errs = append(errs, f.err)
+					reported[p] = true
+				}
+				continue
+			}
+			// the merge modifies the definitions, which are kept for the
+			// next polls
+			sc.Sources = append(sc.Sources, f.schema.Sources...)
+			sc.appendDefinitions(f.schema.clone())
+		}
+		if len(names) > 0 {
+			schemas = append(schemas, *sc)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	defer recoverError(&err)
+	return mergeSchemas(schemas, w.opts.OnConflict), nil
+}
+
+// Watch polls the files every interval and calls fn with the merged
+// schema, or the error, first and after every change, until stop is
+// closed.
+func (w *Watcher) Watch(interval time.Duration, stop <-chan struct{}, fn func(*Schema, error)) {
+	first := true
+	for {
+		changed, err := w.Poll()
+		if err != nil {
+			fn(nil, err)
+		} else if first || len(changed) > 0 {
+			fn(w.Schema())
+		}
+		first = false
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func isGraphQLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".graphql" || ext == ".gql"
+}
+
+// parseFile parses a single GraphQL file, returning the parse error
+// instead of panicking.
+func parseFile(path string) (schema *Schema, err error) {
+	defer recoverError(&err)
+	schema = &Schema{Sources: []*Source{fileSource(path)}}
+	schema.parseSources()
+	return schema, nil
+}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	poll := func(w *Watcher, expected int) *Schema {
+		changed, err := w.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != expected {
+			t.Fatalf("expected %d changed files, got %v", expected, changed)
+		}
+		s, err := w.Schema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	user := func(s *Schema) *Type {
+		for _, t := range s.Types {
+			if t.Name == "User" {
+				return t
+			}
+		}
+		t.Fatal("User not found")
+		return nil
+	}
+
+	write("query.graphql", "type Query { user: User }")
+	write("user.graphql", "type User { id: ID! }")
+
+	w := NewWatcher(dir)
+	if s := poll(w, 2); len(s.Types) != 2 {
+		t.Fatalf("expected 2 types, got %d", len(s.Types))
+	}
+
+	// the definitions kept for the next polls are not modified by a merge
+	write("more.graphql", "type User { name: String }")
+	if s := poll(w, 1); len(s.Types) != 2 || len(user(s).Fields) != 2 {
+		t.Fatalf("expected the merged User, got %+v", s.Types)
+	}
+	if s := poll(w, 0); len(user(s).Fields) != 2 {
+		t.Fatalf("expected User to be merged once, got %d fields", len(user(s).Fields))
+	}
+
+	write("user.graphql", "type User {")
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Schema(); err == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "user.graphql")); err != nil {
+		t.Fatal(err)
+	}
+	if s := poll(w, 1); len(s.Types) != 2 || len(user(s).Fields) != 1 {
+		t.Fatalf("expected User of more.graphql only, got %+v", s.Types)
+	}
+}
+
+func TestWatcherOrder(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, src string) {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("z/user.graphql", "type User { z: String }")
+	write("a/user.graphql", "type User { a: String }")
+	write("a/schema.graphql", "type User { generated: String }")
+
+	paths := []string{filepath.Join(root, "z"), filepath.Join(root, "a")}
+	w := NewWatcher(paths...)
+	if err := w.Ignore(filepath.Join(root, "a", "schema.graphql")); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("expected the output to be ignored, got %v", changed)
+	}
+	watched, err := w.Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the files are merged in the order of a single run
+	if err := os.Remove(filepath.Join(root, "a", "schema.graphql")); err != nil {
+		t.Fatal(err)
+	}
+	merged := mergePaths(MergeOptions{}, paths...)
+	got, expected := (&MergedSchema{}).WriteSchema(watched), (&MergedSchema{}).WriteSchema(merged)
+	if got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
@@ -1,17 +1,109 @@
 package lib
 
 import (
+	"bufio"
+	"io"
 	"sort"
 	"strings"
 )
 
 type MergedSchema struct {
-	buf    strings.Builder
+	buf    schemaWriter
 	Indent string
 }
 
+// Format is the format of the printed schema.
+type Format string
+
+const (
+	// FormatSDL is the GraphQL SDL, the default.
+	FormatSDL Format = "sdl"
+	// FormatASTJSON is the JSON form of the schema written by MarshalAST.
+	FormatASTJSON Format = "ast-json"
+	// FormatIntrospection is the introspection result written by
+	// MarshalIntrospection.
+	FormatIntrospection Format = "introspection"
+)
+
+// Formats are the formats of the printed schema, the default first.
+var Formats = []Format{FormatSDL, FormatASTJSON, FormatIntrospection}
+
+// PrintOptions are the options of Print.
+type PrintOptions struct {
+	// Indent is the padding of the fields, e.g. "  " or "\t"
+	Indent string
+	// Format is FormatSDL when empty. The indent is used by the SDL only.
+	Format Format
+}
+
+// Print returns the schema written in the format of the options, the
+// GraphQL SDL by default. Only the introspection result fails, for a
+// schema which references undefined types.
+func Print(s *Schema, opts PrintOptions) (string, error) {
+	switch opts.Format {
+	case FormatASTJSON:
+		bs, err := MarshalAST(s)
+		return string(bs), err
+	case FormatIntrospection:
+		bs, err := MarshalIntrospection(s)
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+	ms := MergedSchema{Indent: opts.Indent}
+	return ms.WriteSchema(s), nil
+}
+
+// Fprint writes the schema into w like Print.
+func Fprint(w io.Writer, s *Schema, opts PrintOptions) error {
+	switch opts.Format {
+	case FormatASTJSON, FormatIntrospection:
+		ss, err := Print(s, opts)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, ss)
+		return err
+	}
+	ms := MergedSchema{Indent: opts.Indent}
+	return ms.Write(w, s)
+}
+
+// schemaWriter keeps the first error of the writer, so that the printer
+// checks it only once at the end.
+type schemaWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *schemaWriter) WriteString(s string) {
+	if sw.err == nil {
+		_, sw.err = io.WriteString(sw.w, s)
+	}
+}
+
+// WriteSchema returns the schema printed as a string.
 func (ms *MergedSchema) WriteSchema(s *Schema) string {
-	if (s.SchemaDefinitions[0].Query != nil) || (s.SchemaDefinitions[0].Mutation != nil) || (s.SchemaDefinitions[0].Subscription != nil) {
+	var sb strings.Builder
+	// writing into a strings.Builder never fails
+	ms.Write(&sb, s)
+	return sb.String()
+}
+
+// Write prints the schema into w.
+func (ms *MergedSchema) Write(w io.Writer, s *Schema) error {
+	bw := bufio.NewWriter(w)
+	ms.buf = schemaWriter{w: bw}
+	ms.write(s)
+	if ms.buf.err != nil {
+		return ms.buf.err
+	}
+	return bw.Flush()
+}
+
+func (ms *MergedSchema) write(s *Schema) {
+	if len(s.SchemaDefinitions) > 0 && ((s.SchemaDefinitions[0].Query != nil) || (s.SchemaDefinitions[0].Mutation != nil) || (s.SchemaDefinitions[0].Subscription != nil)) {
 		ms.writeDescriptions(s.SchemaDefinitions[0].Descriptions, 0, true)
 		ms.buf.WriteString("schema {\n")
 		ms.addIndent(1)
@@ -78,7 +170,7 @@ func (ms *MergedSchema) WriteSchema(s *Schema) string {
 		ms.stitchDirectives(t.Directives)
 		ms.buf.WriteString(" {\n")
 		for _, p := range t.Fields {
-			ms.writeDescriptions(p.Descriptions, 1, false)
+			ms.writeDescriptions(p.Descriptions, 1, true)
 			ms.addIndent(1)
 			ms.buf.WriteString(p.Name)
 
@@ -143,6 +235,7 @@ func (ms *MergedSchema) WriteSchema(s *Schema) string {
 		ms.stitchDirectives(e.Directives)
 		ms.buf.WriteString(" {\n")
 		for _, n := range e.EnumValues {
+			ms.writeDescriptions(n.Descriptions, 1, true)
 			ms.addIndent(1)
 			ms.buf.WriteString(n.Name)
 			ms.stitchDirectives(n.Directives)
@@ -241,15 +334,8 @@ func (ms *MergedSchema) WriteSchema(s *Schema) string {
 				ms.buf.WriteString("!")
 			}
 			if p.DefaultValues != nil {
-				if p.IsList {
-					ms.buf.WriteString(" = ")
-					ms.buf.WriteString("[")
-					ms.stitchDefaultValues(p.DefaultValues)
-					ms.buf.WriteString("]")
-				} else {
-					ms.buf.WriteString(" = ")
-					ms.stitchDefaultValues(p.DefaultValues)
-				}
+				ms.buf.WriteString(" = ")
+				ms.stitchDefaultValues(p.DefaultValues)
 			}
 			ms.stitchDirectives(p.Directives)
 
@@ -262,7 +348,6 @@ func (ms *MergedSchema) WriteSchema(s *Schema) string {
 		}
 	}
 
-	return ms.buf.String()
 }
 
 func (ms *MergedSchema) addIndent(n int) {
@@ -293,9 +378,7 @@ func (ms *MergedSchema) stitchArgument(a *Arg, l int, i int) {
 		}
 		if a.DefaultValues != nil {
 			ms.buf.WriteString(" = ")
-			ms.buf.WriteString("[")
 			ms.stitchDefaultValues(a.DefaultValues)
-			ms.buf.WriteString("]")
 		}
 		ms.stitchDirectives(a.Directives)
 	} else {
@@ -392,6 +475,10 @@ func (ms *MergedSchema) writeDescriptions(descriptions *[]string, indent int, ne
 	ms.buf.WriteString(ds[0])
 	if newLine {
 		ms.buf.WriteString("\n")
+	} else if strings.HasPrefix(ds[0], "#") {
+		// a comment runs to the end of the line
+		ms.buf.WriteString("\n")
+		ms.addIndent(indent)
 	} else {
 		ms.buf.WriteString(" ")
 	}
@@ -1,34 +1,546 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/mununki/gqlmerge/command"
 	gql "github.com/mununki/gqlmerge/lib"
 )
 
+// subcommands are run by the first argument, which is not a path then.
+var subcommands = map[string]func(args []string){
+	"extract":   extract,
+	"validate":  validate,
+	"lint":      lint,
+	"diff":      diff,
+	"changelog": changelog,
+	"docs":      docs,
+	"lock":      lock,
+	"verify":    verify,
+}
+
 func main() {
-	cmd := command.Command{Args: os.Args}
-	if err := cmd.Check(); err != nil {
-		fmt.Println(err)
-		os.Exit(0)
+	if len(os.Args) > 1 {
+		if sub, ok := subcommands[os.Args[1]]; ok {
+			// a path named like a subcommand is merged when written as
+			// a path, e.g. ./extract
+			if _, err := os.Stat(os.Args[1]); err == nil {
+				fmt.Fprintf(os.Stderr, "⚠️  '%s' runs the subcommand, write './%s' to merge the path\n", os.Args[1], os.Args[1])
+			}
+			sub(os.Args[2:])
+			return
+		}
 	}
 
+	cmd := command.Command{Args: os.Args}
+	checkArgs(cmd.Check())
+
 	// TODO : needs to improve to work with a relative path.
 
-	ss := gql.Merge(cmd.Indent, cmd.Paths...)
+	if len(cmd.Projects) == 0 {
+		if !run(&cmd) {
+			os.Exit(1)
+		}
+		return
+	}
 
-	if ss != nil {
-		bs := []byte(*ss)
-		err := os.WriteFile(cmd.Output, bs, 0644)
+	if cmd.Watch && len(cmd.Projects) > 1 {
+		fmt.Fprintln(os.Stderr, "😱 Only a single project can be watched, pick one with -project")
+		os.Exit(1)
+	}
+
+	ok := true
+	for _, p := range cmd.Projects {
+		fmt.Fprintf(os.Stderr, "📁 Project '%s'\n", p.Name)
+		if !run(p) {
+			ok = false
+		}
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// run merges the paths, or the project, and writes or checks the outputs.
+// It reports whether it succeeded.
+func run(cmd *command.Command) bool {
+	gql.UseCache(nil)
+
+	if cmd.CacheDir != "" {
+		c, err := gql.NewCache(cmd.CacheDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+			return false
+		}
+		gql.UseCache(c)
+	}
+
+	contracts := make([]gql.Contract, 0, len(cmd.Contracts))
+	for _, c := range cmd.Contracts {
+		contracts = append(contracts, c.Contract)
+	}
+
+	if cmd.Watch {
+		watch(cmd, contracts)
+		return true
+	}
+
+	s, _, err := gql.MergeSchemaWith(mergeOptions(cmd), cmd.Paths...)
+
+	if cmd.CacheDir != "" {
+		fmt.Fprintf(os.Stderr, "📦 Cache: %s\n", gql.CurrentCache())
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		return false
+	}
+
+	if s == nil {
+		fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+		return true
+	}
+
+	if !checkSchema(cmd, s) {
+		return false
+	}
+
+	opts := gql.PrintOptions{Indent: cmd.Indent, Format: cmd.Format}
+	cs := make([]string, 0, len(contracts))
+	for _, c := range contracts {
+		ss, err := gql.Print(s.ApplyContract(c), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+			return false
+		}
+		cs = append(cs, ss)
+	}
+	ss, err := gql.Print(s, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		return false
+	}
+
+	if cmd.CheckOnly {
+		outputs := map[string]string{cmd.Output: ss}
+		names := []string{cmd.Output}
+		for i, c := range cmd.Contracts {
+			outputs[c.Output] = cs[i]
+			names = append(names, c.Output)
+		}
+		return check(names, outputs)
+	}
+
+	err = writeOutput(cmd.Output, ss)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file: %s\n", cmd.Output, err)
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 Successfully generated '%s'\n", outputName(cmd.Output))
+
+	for i, c := range cmd.Contracts {
+		err := writeOutput(c.Output, cs[i])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file: %s\n", c.Output, err)
+			return false
+		}
+
+		fmt.Fprintf(os.Stderr, "👍 Successfully generated '%s' for the contract '%s'\n", outputName(c.Output), c.Name)
+	}
+
+	return runPlugins(cmd)
+}
+
+// mergeOptions returns how the files of the project are selected and
+// merged.
+func mergeOptions(cmd *command.Command) gql.MergeOptions {
+	return gql.MergeOptions{Filter: cmd.Filter, OnConflict: cmd.OnConflict}
+}
+
+// runPlugins runs the plugins of the project with the output as the last
+// argument, in the directory of the configuration file.
+func runPlugins(cmd *command.Command) bool {
+	for _, plugin := range cmd.Plugins {
+		args := strings.Fields(plugin)
+		if len(args) == 0 {
+			continue
+		}
+
+		output := cmd.Output
+		if abs, err := filepath.Abs(output); err == nil && output != command.Stdout {
+			output = abs
+		}
+		c := exec.Command(args[0], append(args[1:], output)...)
+		c.Dir = cmd.Dir
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "😱 Plugin '%s' failed: %s\n", plugin, err)
+			return false
+		}
+
+		fmt.Fprintf(os.Stderr, "🔌 Plugin '%s' done\n", plugin)
+	}
+	return true
+}
+
+// watch merges the files whenever they change and writes the outputs which
+// change. The problems are printed without exiting.
+func watch(cmd *command.Command, contracts []gql.Contract) {
+	fmt.Fprintf(os.Stderr, "👀 Watching %v for changes, press Ctrl+C to stop\n", cmd.Paths)
+
+	// the outputs written into a watched directory are not sources
+	outputs := []string{}
+	if cmd.Output != command.Stdout {
+		outputs = append(outputs, cmd.Output)
+	}
+	for _, c := range cmd.Contracts {
+		outputs = append(outputs, c.Output)
+	}
+
+	w := gql.NewWatcherWith(mergeOptions(cmd), cmd.Paths...)
+	if err := w.Ignore(outputs...); err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		return
+	}
+	w.Watch(time.Second, nil, func(s *gql.Schema, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+			return
+		}
+		if s == nil {
+			fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+			return
+		}
+
+		checkSchema(cmd, s)
+
+		opts := gql.PrintOptions{Indent: cmd.Indent, Format: cmd.Format}
+		ss, err := gql.Print(s, opts)
 		if err != nil {
-			fmt.Printf("😱 Error in writing '%s' file", cmd.Output)
+			fmt.Fprintf(os.Stderr, "😱 %s\n", err)
 			return
 		}
+		writeIfChanged(cmd.Output, ss)
+		for i, c := range cmd.Contracts {
+			cs, err := gql.Print(s.ApplyContract(contracts[i]), opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+				return
+			}
+			writeIfChanged(c.Output, cs)
+		}
+	})
+}
 
-		fmt.Printf("👍 Successfully generated '%s'\n", cmd.Output)
+// checkSchema prints the undefined types of the merged schema, or all its
+// problems with -validate, and the lint problems with the lint rules of the
+// project. It reports whether there is no error among them.
+func checkSchema(cmd *command.Command, s *gql.Schema) bool {
+	if cmd.Validate {
+		if !printDiagnostics(gql.Validate(s)) {
+			return false
+		}
 	} else {
-		fmt.Printf("😳 Not found any GraphQL files in %v\n", cmd.Paths)
+		for _, d := range gql.UndefinedTypes(s) {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", d)
+		}
+	}
+
+	if cmd.Lint != nil {
+		ds, err := gql.Lint(s, gql.LintOptions{Rules: cmd.Lint})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+			return false
+		}
+		return printDiagnostics(ds)
+	}
+	return true
+}
+
+// writeOutput writes the content into the file, or into the standard
+// output for "-".
+func writeOutput(name, content string) error {
+	if name == command.Stdout {
+		_, err := io.WriteString(os.Stdout, content)
+		return err
+	}
+	return os.WriteFile(name, []byte(content), 0644)
+}
+
+// outputName returns the name of the output in the messages.
+func outputName(name string) string {
+	if name == command.Stdout {
+		return "<stdout>"
+	}
+	return name
+}
+
+// writeIfChanged writes the file unless it has the content already.
+func writeIfChanged(name, content string) {
+	if name == command.Stdout {
+		writeOutput(name, content)
+		return
+	}
+
+	if bs, err := os.ReadFile(name); err == nil && string(bs) == content {
+		return
+	}
+
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file\n", name)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 Successfully generated '%s'\n", name)
+}
+
+// check compares the outputs with the existing files, printing their
+// differences, and reports whether they are all up to date.
+func check(names []string, outputs map[string]string) bool {
+	outdated := 0
+	for _, name := range names {
+		// a missing file is compared as an empty one
+		bs, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "😱 Error in reading '%s' file\n", name)
+			return false
+		}
+
+		if d := gql.UnifiedDiff(name, name, string(bs), outputs[name]); d != "" {
+			fmt.Print(d)
+			outdated++
+		}
+	}
+
+	if outdated > 0 {
+		fmt.Fprintf(os.Stderr, "😱 %d of %d files are not up to date\n", outdated, len(names))
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 %d files are up to date\n", len(names))
+	return true
+}
+
+func extract(args []string) {
+	cmd := command.Extract{Args: args}
+	checkArgs(cmd.Check())
+
+	ss, err := gql.Extract(cmd.Indent, cmd.Fields, cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+
+	if ss != nil {
+		err := writeOutput(cmd.Output, *ss)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file: %s\n", cmd.Output, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(os.Stderr, "👍 Successfully generated '%s'\n", outputName(cmd.Output))
+	} else {
+		fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+	}
+}
+
+func validate(args []string) {
+	cmd := command.Validate{Args: args}
+	checkArgs(cmd.Check())
+
+	ss, ds, err := gql.MergeAndValidate("", cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+	if ss == nil {
+		fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+		return
+	}
+
+	if !printDiagnostics(ds) {
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(os.Stderr, "👍 The schema is valid")
+}
+
+func lint(args []string) {
+	cmd := command.Lint{Args: args}
+	checkArgs(cmd.Check())
+
+	ds, err := gql.MergeAndLint(gql.LintOptions{Rules: cmd.Rules}, cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+
+	if !printDiagnostics(ds) {
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(os.Stderr, "👍 No lint errors found")
+}
+
+func diff(args []string) {
+	cmd := command.Diff{Args: args}
+	checkArgs(cmd.Check())
+
+	cs, err := gql.DiffPaths([]string{cmd.Old}, cmd.Paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😳 %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, c := range cs {
+		fmt.Println(c)
+	}
+
+	if cs.HasBreaking() {
+		breaking := 0
+		for _, c := range cs {
+			if c.Criticality == gql.Breaking {
+				breaking++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "😱 %d breaking changes found\n", breaking)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 No breaking changes, %d changes found\n", len(cs))
+}
+
+func changelog(args []string) {
+	cmd := command.Changelog{Args: args}
+	checkArgs(cmd.Check())
+
+	ss, err := gql.ChangelogPaths([]string{cmd.Old}, cmd.Paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😳 %s\n", err)
+		os.Exit(1)
+	}
+
+	err = writeOutput(cmd.Output, *ss)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file: %s\n", cmd.Output, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 Successfully generated '%s'\n", outputName(cmd.Output))
+}
+
+func docs(args []string) {
+	cmd := command.Docs{Args: args}
+	checkArgs(cmd.Check())
+
+	s, ds, err := gql.MergeSchema(cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+	if s == nil {
+		fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+		return
+	}
+
+	for _, d := range ds {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", d)
+	}
+
+	n, err := gql.WriteDocs(cmd.Output, s, cmd.Format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 Error in writing the docs into '%s': %s\n", cmd.Output, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 Successfully generated %d pages in '%s'\n", n, cmd.Output)
+}
+
+func lock(args []string) {
+	cmd := command.Lock{Args: args}
+	checkArgs(cmd.Check())
+
+	l, err := gql.MergeAndLock(cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+	if l == nil {
+		fmt.Fprintf(os.Stderr, "😳 Not found any GraphQL files in %v\n", cmd.Paths)
+		os.Exit(1)
+	}
+
+	if err := l.Write(cmd.Lockfile); err != nil {
+		fmt.Fprintf(os.Stderr, "😱 Error in writing '%s' file: %s\n", cmd.Lockfile, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "👍 Successfully locked the schema in '%s'\n", cmd.Lockfile)
+}
+
+func verify(args []string) {
+	cmd := command.Verify{Args: args}
+	checkArgs(cmd.Check())
+
+	cs, err := gql.MergeAndVerify(cmd.Lockfile, cmd.Paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "😱 %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(cs) == 0 {
+		fmt.Fprintf(os.Stderr, "👍 The schema matches '%s'\n", cmd.Lockfile)
+		return
+	}
+
+	for _, c := range cs {
+		fmt.Println(c)
+	}
+
+	if cs.HasBreaking() && !cmd.AcceptBreaking {
+		fmt.Fprintf(os.Stderr, "😱 The schema differs from '%s' in a breaking way, run 'gqlmerge lock' with the accepted changes or -accept-breaking\n", cmd.Lockfile)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  The schema differs from '%s', run 'gqlmerge lock' to update it\n", cmd.Lockfile)
+}
+
+// checkArgs exits when the arguments are wrong, printing the error and the
+// usage, or when the help or the version is asked for, printing it.
+func checkArgs(err error) {
+	if err == nil {
+		return
+	}
+
+	var m command.Message
+	if errors.As(err, &m) {
+		fmt.Println(m)
+		os.Exit(0)
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// printDiagnostics prints the diagnostics and reports whether there is no
+// error among them.
+func printDiagnostics(ds gql.Diagnostics) bool {
+	for _, d := range ds {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	if ds.HasErrors() {
+		fmt.Fprintf(os.Stderr, "😱 %d problems found\n", len(ds))
+		return false
 	}
+	return true
 }